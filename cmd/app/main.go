@@ -1,23 +1,227 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"go-backend/config"
 	"go-backend/database"
+	"go-backend/internal/cache"
+	"go-backend/internal/grpcapi"
+	"go-backend/internal/health"
+	"go-backend/internal/models"
+	"go-backend/internal/repositories"
 	"go-backend/internal/routers"
+	"go-backend/internal/services"
+	"go-backend/pkg/logger"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+	"go.yaml.in/yaml/v3"
+	"google.golang.org/grpc"
 )
 
 func main() {
-	cfg, err := config.Load()
+	args := os.Args[1:]
+	if len(args) >= 2 && args[0] == "config" && args[1] == "print" {
+		printConfig(args[2:])
+		return
+	}
+	if len(args) >= 2 && args[0] == "brands" && args[1] == "normalize" {
+		normalizeBrands(args[2:])
+		return
+	}
+	if len(args) >= 1 && args[0] == "openapi" {
+		printSpec()
+		return
+	}
+	cfg, err := config.Load(args)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	lg, err := logger.New(cfg.Logging.Level, cfg.Logging.Format)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	defer func() { _ = lg.Sync() }()
+	db, err := database.Connect(cfg.Database.Path)
+	if err != nil {
+		lg.Fatal("connect database", zap.Error(err))
+	}
+	deprecation, sunset, err := cfg.API.Unversioned()
+	if err != nil {
+		lg.Fatal("api versions", zap.Error(err))
+	}
+	status := health.NewStatus()
+	status.SetTimeout(cfg.Health.CheckTimeout)
+	status.Register("disk", health.DiskSpace(filepath.Dir(cfg.Database.Path), cfg.Health.MinFreeMB<<20))
+	var deviceOpts []services.DeviceServiceOption
+	if cfg.DeviceCache.Size > 0 {
+		deviceOpts = append(deviceOpts, services.WithCache(cache.NewLRU[int64, models.Device](cfg.DeviceCache.Size, cfg.DeviceCache.TTL)))
+	}
+	devices := services.NewDeviceService(repositories.NewDeviceRepository(db, repositories.WithBrandCreation(cfg.Brands.AutoCreate)), deviceOpts...)
+	r := routers.New(db,
+		routers.WithDeviceService(devices),
+		routers.WithHealth(status),
+		routers.WithAuth(cfg.Auth),
+		routers.WithCORS(cfg.CORS),
+		routers.WithSpecValidation(cfg.OpenAPI.ValidateRequests, cfg.OpenAPI.ValidateResponses),
+		routers.WithHeartbeat(cfg.Events.HeartbeatInterval),
+		routers.WithGraphQLLimits(cfg.GraphQL.MaxDepth, cfg.GraphQL.MaxComplexity),
+		routers.WithUnversionedDeprecation(deprecation, sunset),
+		routers.WithTimeFormat(models.TimeFormat(cfg.API.TimeFormat)),
+		routers.WithHTTPCache(cfg.HTTPCache),
+		routers.WithLabels(cfg.Labels),
+		routers.WithLeases(cfg.Leases),
+		routers.WithWebhooks(cfg.Webhooks),
+	)
+	srv := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           r,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	dispatchCtx, stopDispatch := context.WithCancel(context.Background())
+	var dispatching sync.WaitGroup
+	dispatcher := services.NewWebhookDispatcher(repositories.NewWebhookRepository(db), cfg.Webhooks, models.TimeFormat(cfg.API.TimeFormat))
+	dispatching.Add(1)
+	go func() {
+		defer dispatching.Done()
+		dispatcher.Run(dispatchCtx, func(err error) { lg.Error("dispatch webhooks", zap.Error(err)) })
+	}()
+	reservations := services.NewReservationService(repositories.NewReservationRepository(db), devices)
+	dispatching.Add(1)
+	go func() {
+		defer dispatching.Done()
+		reservations.Run(dispatchCtx, cfg.Reservations.PollInterval, func(err error) { lg.Error("process reservations", zap.Error(err)) })
+	}()
+	leases := services.NewLeaseService(repositories.NewLeaseRepository(db), devices, cfg.Leases)
+	dispatching.Add(1)
+	go func() {
+		defer dispatching.Done()
+		leases.Run(dispatchCtx, cfg.Leases.ReapInterval, func(err error) { lg.Error("reap leases", zap.Error(err)) })
+	}()
+	errCh := make(chan error, 1)
+	go func() {
+		lg.Info("listening", zap.String("addr", cfg.Server.Addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	grpcSrv, grpcHealth := grpcapi.New(devices)
+	grpcErrCh := make(chan error, 1)
+	if cfg.GRPC.Addr != "" {
+		lis, err := net.Listen("tcp", cfg.GRPC.Addr)
+		if err != nil {
+			lg.Fatal("listen grpc", zap.Error(err))
+		}
+		go func() {
+			lg.Info("listening grpc", zap.String("addr", cfg.GRPC.Addr))
+			grpcErrCh <- grpcSrv.Serve(lis)
+		}()
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			lg.Fatal("serve", zap.Error(err))
+		}
+	case err := <-grpcErrCh:
+		lg.Fatal("serve grpc", zap.Error(err))
+	case <-ctx.Done():
+	}
+	stop()
+	lg.Info("shutting down")
+
+	// Fail readiness first so the load balancer stops routing new traffic
+	// before we stop accepting connections.
+	status.SetReady(false)
+	grpcHealth.Shutdown()
+	time.Sleep(cfg.Server.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		lg.Error("shutdown", zap.Error(err))
+	}
+	stopGRPC(shutdownCtx, grpcSrv)
+	// Undelivered events stay in the outbox and are picked up on restart, as
+	// are reservations and expired leases due in the meantime.
+	stopDispatch()
+	dispatching.Wait()
+	if err := database.Close(db); err != nil {
+		lg.Error("close database", zap.Error(err))
+	}
+}
+
+// stopGRPC waits for in-flight RPCs until ctx expires and then cancels the
+// rest; Watch streams only end once cancelled.
+func stopGRPC(ctx context.Context, srv *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		srv.Stop()
+	}
+}
+
+func printConfig(args []string) {
+	cfg, err := config.Load(args)
 	if err != nil {
 		log.Fatalf("%v", err)
 	}
-	db, err := database.Connect(cfg.DBPath)
+	out, err := cfg.YAML()
 	if err != nil {
 		log.Fatalf("%v", err)
 	}
-	r := routers.New(db)
-	if err := r.Run(cfg.ServerAddr); err != nil {
+	fmt.Print(string(out))
+}
+
+// normalizeBrands points every device at the brand its free-text brand
+// resolves to, creating brands for unknown names, and removes brands that
+// have become aliases of another brand.
+func normalizeBrands(args []string) {
+	cfg, err := config.Load(args)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	db, err := database.Connect(cfg.Database.Path)
+	if err != nil {
+		log.Fatalf("connect database: %v", err)
+	}
+	defer func() { _ = database.Close(db) }()
+	devices := services.NewDeviceService(repositories.NewDeviceRepository(db))
+	brands := services.NewBrandService(repositories.NewBrandRepository(db), devices)
+	changed, removed, err := brands.Normalize(context.Background())
+	if err != nil {
+		log.Fatalf("normalize brands: %v", err)
+	}
+	fmt.Printf("devices updated: %d\nbrands removed: %d\n", changed, removed)
+}
+
+func printSpec() {
+	out, err := yaml.Marshal(routers.Spec())
+	if err != nil {
 		log.Fatalf("%v", err)
 	}
+	fmt.Print(string(out))
 }
@@ -1,22 +1,294 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
 
 type Config struct {
-	DBPath     string
-	ServerAddr string
-}
-
-func Load() (*Config, error) {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath("./config")
-	viper.SetDefault("DB_PATH", "./data/devices.db")
-	viper.SetDefault("SERVER_ADDR", ":8080")
-	viper.AutomaticEnv()
-	_ = viper.ReadInConfig()
-	return &Config{
-		DBPath:     viper.GetString("DB_PATH"),
-		ServerAddr: viper.GetString("SERVER_ADDR"),
-	}, nil
+	Server       ServerConfig       `mapstructure:"server" yaml:"server"`
+	GRPC         GRPCConfig         `mapstructure:"grpc" yaml:"grpc"`
+	Database     DatabaseConfig     `mapstructure:"database" yaml:"database"`
+	Logging      LoggingConfig      `mapstructure:"logging" yaml:"logging"`
+	Auth         AuthConfig         `mapstructure:"auth" yaml:"auth"`
+	CORS         CORSConfig         `mapstructure:"cors" yaml:"cors"`
+	Health       HealthConfig       `mapstructure:"health" yaml:"health"`
+	OpenAPI      OpenAPIConfig      `mapstructure:"openapi" yaml:"openapi"`
+	Events       EventsConfig       `mapstructure:"events" yaml:"events"`
+	Webhooks     WebhooksConfig     `mapstructure:"webhooks" yaml:"webhooks"`
+	GraphQL      GraphQLConfig      `mapstructure:"graphql" yaml:"graphql"`
+	API          APIConfig          `mapstructure:"api" yaml:"api"`
+	HTTPCache    HTTPCacheConfig    `mapstructure:"http_cache" yaml:"http_cache"`
+	DeviceCache  DeviceCacheConfig  `mapstructure:"device_cache" yaml:"device_cache"`
+	Brands       BrandsConfig       `mapstructure:"brands" yaml:"brands"`
+	Labels       LabelsConfig       `mapstructure:"labels" yaml:"labels"`
+	Reservations ReservationsConfig `mapstructure:"reservations" yaml:"reservations"`
+	Leases       LeasesConfig       `mapstructure:"leases" yaml:"leases"`
+}
+
+type ServerConfig struct {
+	Addr              string        `mapstructure:"addr" yaml:"addr"`
+	ReadTimeout       time.Duration `mapstructure:"read_timeout" yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout" yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `mapstructure:"write_timeout" yaml:"write_timeout"`
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout" yaml:"idle_timeout"`
+	ShutdownDelay     time.Duration `mapstructure:"shutdown_delay" yaml:"shutdown_delay"`
+	ShutdownTimeout   time.Duration `mapstructure:"shutdown_timeout" yaml:"shutdown_timeout"`
+}
+
+// GRPCConfig configures the gRPC listener; an empty Addr disables it.
+type GRPCConfig struct {
+	Addr string `mapstructure:"addr" yaml:"addr"`
+}
+
+type DatabaseConfig struct {
+	Path string `mapstructure:"path" yaml:"path"`
+}
+
+type LoggingConfig struct {
+	Level  string `mapstructure:"level" yaml:"level"`
+	Format string `mapstructure:"format" yaml:"format"`
+}
+
+type AuthConfig struct {
+	Enabled   bool     `mapstructure:"enabled" yaml:"enabled"`
+	JWTSecret string   `mapstructure:"jwt_secret" yaml:"jwt_secret"`
+	APIKeys   []string `mapstructure:"api_keys" yaml:"api_keys"`
+}
+
+type CORSConfig struct {
+	AllowedOrigins        []string      `mapstructure:"allowed_origins" yaml:"allowed_origins"`
+	AllowedOriginPatterns []string      `mapstructure:"allowed_origin_patterns" yaml:"allowed_origin_patterns"`
+	AllowedMethods        []string      `mapstructure:"allowed_methods" yaml:"allowed_methods"`
+	AllowedHeaders        []string      `mapstructure:"allowed_headers" yaml:"allowed_headers"`
+	ExposedHeaders        []string      `mapstructure:"exposed_headers" yaml:"exposed_headers"`
+	AllowCredentials      bool          `mapstructure:"allow_credentials" yaml:"allow_credentials"`
+	MaxAge                time.Duration `mapstructure:"max_age" yaml:"max_age"`
+}
+
+type HealthConfig struct {
+	CheckTimeout time.Duration `mapstructure:"check_timeout" yaml:"check_timeout"`
+	MinFreeMB    uint64        `mapstructure:"min_free_mb" yaml:"min_free_mb"`
+}
+
+type OpenAPIConfig struct {
+	ValidateRequests  bool `mapstructure:"validate_requests" yaml:"validate_requests"`
+	ValidateResponses bool `mapstructure:"validate_responses" yaml:"validate_responses"`
+}
+
+type EventsConfig struct {
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval" yaml:"heartbeat_interval"`
+}
+
+type WebhooksConfig struct {
+	PollInterval         time.Duration `mapstructure:"poll_interval" yaml:"poll_interval"`
+	MaxAttempts          int           `mapstructure:"max_attempts" yaml:"max_attempts"`
+	InitialBackoff       time.Duration `mapstructure:"initial_backoff" yaml:"initial_backoff"`
+	MaxBackoff           time.Duration `mapstructure:"max_backoff" yaml:"max_backoff"`
+	Timeout              time.Duration `mapstructure:"timeout" yaml:"timeout"`
+	Retention            time.Duration `mapstructure:"retention" yaml:"retention"`
+	AllowedHosts         []string      `mapstructure:"allowed_hosts" yaml:"allowed_hosts"`
+	AllowPrivateNetworks bool          `mapstructure:"allow_private_networks" yaml:"allow_private_networks"`
+}
+
+type GraphQLConfig struct {
+	MaxDepth      int `mapstructure:"max_depth" yaml:"max_depth"`
+	MaxComplexity int `mapstructure:"max_complexity" yaml:"max_complexity"`
+}
+
+// APIConfig dates the deprecation of the unprefixed aliases of the /v1
+// routes, both RFC 3339 timestamps with an empty sunset announcing no date,
+// and sets the default time format of responses.
+type APIConfig struct {
+	UnversionedDeprecation string `mapstructure:"unversioned_deprecation" yaml:"unversioned_deprecation"`
+	UnversionedSunset      string `mapstructure:"unversioned_sunset" yaml:"unversioned_sunset"`
+	TimeFormat             string `mapstructure:"time_format" yaml:"time_format"`
+}
+
+// Unversioned parses the deprecation and sunset dates of the unprefixed routes.
+func (a APIConfig) Unversioned() (deprecation, sunset time.Time, err error) {
+	if deprecation, err = time.Parse(time.RFC3339, a.UnversionedDeprecation); err != nil {
+		return deprecation, sunset, fmt.Errorf("api.unversioned_deprecation: %w", err)
+	}
+	if a.UnversionedSunset != "" {
+		if sunset, err = time.Parse(time.RFC3339, a.UnversionedSunset); err != nil {
+			return deprecation, sunset, fmt.Errorf("api.unversioned_sunset: %w", err)
+		}
+	}
+	return deprecation, sunset, nil
+}
+
+// HTTPCacheConfig sets the Cache-Control header of cacheable routes; an
+// empty value sends none.
+type HTTPCacheConfig struct {
+	GetDevice   string `mapstructure:"get_device" yaml:"get_device"`
+	ListDevices string `mapstructure:"list_devices" yaml:"list_devices"`
+}
+
+// DeviceCacheConfig bounds the in-memory cache of device reads; a Size of 0
+// disables it.
+type DeviceCacheConfig struct {
+	Size int           `mapstructure:"size" yaml:"size"`
+	TTL  time.Duration `mapstructure:"ttl" yaml:"ttl"`
+}
+
+// BrandsConfig sets whether writing a device with an unknown brand creates
+// the brand or is refused.
+type BrandsConfig struct {
+	AutoCreate bool `mapstructure:"auto_create" yaml:"auto_create"`
+}
+
+// LabelsConfig sets what device label QR codes link to; {id} in DeviceURL is
+// replaced by the device ID. MaxBatch bounds the labels of one PDF.
+type LabelsConfig struct {
+	DeviceURL string `mapstructure:"device_url" yaml:"device_url"`
+	MaxBatch  int    `mapstructure:"max_batch" yaml:"max_batch"`
+}
+
+// ReservationsConfig sets how often reservations are started and ended, and
+// so how late a device may change state after a window opens or closes.
+type ReservationsConfig struct {
+	PollInterval time.Duration `mapstructure:"poll_interval" yaml:"poll_interval"`
+}
+
+// LeasesConfig bounds the TTL of device leases; DefaultTTL applies when a
+// request names none. Expired leases are reaped every ReapInterval.
+type LeasesConfig struct {
+	DefaultTTL   time.Duration `mapstructure:"default_ttl" yaml:"default_ttl"`
+	MaxTTL       time.Duration `mapstructure:"max_ttl" yaml:"max_ttl"`
+	ReapInterval time.Duration `mapstructure:"reap_interval" yaml:"reap_interval"`
+}
+
+var defaults = map[string]any{
+	"server.addr":                     ":8080",
+	"server.read_timeout":             "15s",
+	"server.read_header_timeout":      "5s",
+	"server.write_timeout":            "30s",
+	"server.idle_timeout":             "120s",
+	"server.shutdown_delay":           "0s",
+	"server.shutdown_timeout":         "20s",
+	"grpc.addr":                       ":9090",
+	"database.path":                   "./data/devices.db",
+	"logging.level":                   "info",
+	"logging.format":                  "json",
+	"auth.enabled":                    false,
+	"auth.jwt_secret":                 "",
+	"auth.api_keys":                   []string{},
+	"cors.allowed_origins":            []string{},
+	"cors.allowed_origin_patterns":    []string{},
+	"cors.allowed_methods":            []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+	"cors.allowed_headers":            []string{"Content-Type", "Authorization"},
+	"cors.exposed_headers":            []string{},
+	"cors.allow_credentials":          false,
+	"cors.max_age":                    "10m",
+	"health.check_timeout":            "2s",
+	"health.min_free_mb":              64,
+	"openapi.validate_requests":       false,
+	"openapi.validate_responses":      false,
+	"events.heartbeat_interval":       "15s",
+	"webhooks.poll_interval":          "1s",
+	"webhooks.max_attempts":           8,
+	"webhooks.initial_backoff":        "5s",
+	"webhooks.max_backoff":            "1h",
+	"webhooks.timeout":                "10s",
+	"webhooks.retention":              "168h",
+	"webhooks.allowed_hosts":          []string{},
+	"webhooks.allow_private_networks": false,
+	"graphql.max_depth":               8,
+	"graphql.max_complexity":          1000,
+	"api.unversioned_deprecation":     "2026-10-19T00:00:00Z",
+	"api.unversioned_sunset":          "",
+	"api.time_format":                 "legacy",
+	"http_cache.get_device":           "private, no-cache",
+	"http_cache.list_devices":         "private, no-cache",
+	"device_cache.size":               10000,
+	"device_cache.ttl":                "30s",
+	"brands.auto_create":              true,
+	"labels.device_url":               "http://localhost:8080/v1/devices/{id}",
+	"labels.max_batch":                500,
+	"reservations.poll_interval":      "10s",
+	"leases.default_ttl":              "8h",
+	"leases.max_ttl":                  "168h",
+	"leases.reap_interval":            "30s",
+}
+
+// Legacy environment variable names that predate the sectioned layout.
+var envAliases = map[string]string{
+	"database.path": "DB_PATH",
+}
+
+func newViper() *viper.Viper {
+	v := viper.New()
+	for k, val := range defaults {
+		v.SetDefault(k, val)
+	}
+	return v
+}
+
+// Default returns the built-in defaults without consulting files, env or flags.
+func Default() *Config {
+	var cfg Config
+	if err := newViper().Unmarshal(&cfg); err != nil {
+		panic(err)
+	}
+	return &cfg
+}
+
+// Load builds the configuration from, in increasing order of precedence,
+// defaults, the YAML file, environment variables and command line flags.
+// A missing default config file is not an error; a missing file passed via
+// --config, a malformed file or an unknown key is.
+func Load(args []string) (*Config, error) {
+	fs := pflag.NewFlagSet("app", pflag.ContinueOnError)
+	configFile := fs.String("config", "", "path to a YAML config file")
+	fs.String("server.addr", "", "server listen address")
+	fs.String("grpc.addr", "", "gRPC listen address, empty to disable")
+	fs.String("database.path", "", "path to the SQLite database file")
+	fs.String("logging.level", "", "log level (debug, info, warn, error)")
+	fs.String("logging.format", "", "log format (json, console)")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	v := newViper()
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	for key, env := range envAliases {
+		if err := v.BindEnv(key, strings.ToUpper(strings.ReplaceAll(key, ".", "_")), env); err != nil {
+			return nil, err
+		}
+	}
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Name != "config" {
+			_ = v.BindPFlag(f.Name, f)
+		}
+	})
+
+	if *configFile != "" {
+		v.SetConfigFile(*configFile)
+	} else {
+		v.SetConfigName("config")
+		v.SetConfigType("yaml")
+		v.AddConfigPath("./config")
+	}
+	if err := v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if *configFile != "" || !errors.As(err, &notFound) {
+			return nil, fmt.Errorf("read config: %w", err)
+		}
+	}
+
+	var cfg Config
+	if err := v.UnmarshalExact(&cfg); err != nil {
+		return nil, fmt.Errorf("decode config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
 }
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const redacted = "[REDACTED]"
+
+// Validate checks the whole configuration and reports every problem at once.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) { errs = append(errs, fmt.Errorf(format, args...)) }
+
+	if c.Server.Addr == "" {
+		fail("server.addr must not be empty")
+	}
+	if c.GRPC.Addr != "" && c.GRPC.Addr == c.Server.Addr {
+		fail("grpc.addr must differ from server.addr")
+	}
+	for name, d := range map[string]time.Duration{
+		"server.read_timeout":        c.Server.ReadTimeout,
+		"server.read_header_timeout": c.Server.ReadHeaderTimeout,
+		"server.write_timeout":       c.Server.WriteTimeout,
+		"server.idle_timeout":        c.Server.IdleTimeout,
+		"server.shutdown_delay":      c.Server.ShutdownDelay,
+		"server.shutdown_timeout":    c.Server.ShutdownTimeout,
+		"health.check_timeout":       c.Health.CheckTimeout,
+		"cors.max_age":               c.CORS.MaxAge,
+	} {
+		if d < 0 {
+			fail("%s must not be negative", name)
+		}
+	}
+	if c.Events.HeartbeatInterval <= 0 {
+		fail("events.heartbeat_interval must be positive")
+	}
+	for name, d := range map[string]time.Duration{
+		"webhooks.poll_interval":     c.Webhooks.PollInterval,
+		"webhooks.initial_backoff":   c.Webhooks.InitialBackoff,
+		"webhooks.max_backoff":       c.Webhooks.MaxBackoff,
+		"webhooks.timeout":           c.Webhooks.Timeout,
+		"webhooks.retention":         c.Webhooks.Retention,
+		"reservations.poll_interval": c.Reservations.PollInterval,
+		"leases.default_ttl":         c.Leases.DefaultTTL,
+		"leases.max_ttl":             c.Leases.MaxTTL,
+		"leases.reap_interval":       c.Leases.ReapInterval,
+	} {
+		if d <= 0 {
+			fail("%s must be positive", name)
+		}
+	}
+	if c.Webhooks.MaxAttempts < 1 {
+		fail("webhooks.max_attempts must be at least 1")
+	}
+	if c.Leases.DefaultTTL > c.Leases.MaxTTL {
+		fail("leases.default_ttl must not exceed leases.max_ttl")
+	}
+	if c.GraphQL.MaxDepth < 1 || c.GraphQL.MaxComplexity < 1 {
+		fail("graphql.max_depth and graphql.max_complexity must be at least 1")
+	}
+	if deprecation, sunset, err := c.API.Unversioned(); err != nil {
+		fail("%v", err)
+	} else if !sunset.IsZero() && !sunset.After(deprecation) {
+		fail("api.unversioned_sunset must be after api.unversioned_deprecation")
+	}
+	if !slices.Contains([]string{"rfc3339", "epoch", "legacy"}, c.API.TimeFormat) {
+		fail("api.time_format must be one of rfc3339, epoch, legacy")
+	}
+	if c.DeviceCache.Size < 0 {
+		fail("device_cache.size must not be negative")
+	}
+	if c.DeviceCache.Size > 0 && c.DeviceCache.TTL <= 0 {
+		fail("device_cache.ttl must be positive when the cache is enabled")
+	}
+	if !strings.Contains(c.Labels.DeviceURL, "{id}") {
+		fail("labels.device_url must contain {id}")
+	}
+	if c.Labels.MaxBatch < 1 {
+		fail("labels.max_batch must be at least 1")
+	}
+	if c.Database.Path == "" {
+		fail("database.path must not be empty")
+	}
+	if !slices.Contains([]string{"debug", "info", "warn", "error"}, c.Logging.Level) {
+		fail("logging.level must be one of debug, info, warn, error")
+	}
+	if !slices.Contains([]string{"json", "console"}, c.Logging.Format) {
+		fail("logging.format must be one of json, console")
+	}
+	if c.Auth.Enabled && c.Auth.JWTSecret == "" && len(c.Auth.APIKeys) == 0 {
+		fail("auth.enabled requires auth.jwt_secret or auth.api_keys")
+	}
+	if c.Auth.JWTSecret != "" && len(c.Auth.JWTSecret) < 32 {
+		fail("auth.jwt_secret must be at least 32 characters")
+	}
+	if slices.Contains(c.CORS.AllowedOrigins, "*") {
+		fail("cors.allowed_origins must list explicit origins, not *")
+	}
+	for _, p := range c.CORS.AllowedOriginPatterns {
+		if _, err := path.Match(p, ""); err != nil {
+			fail("cors.allowed_origin_patterns: invalid pattern %q", p)
+		}
+	}
+	for _, p := range c.Webhooks.AllowedHosts {
+		if _, err := path.Match(p, ""); err != nil {
+			fail("webhooks.allowed_hosts: invalid pattern %q", p)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Redacted returns a copy of the configuration with secrets masked.
+func (c Config) Redacted() Config {
+	if c.Auth.JWTSecret != "" {
+		c.Auth.JWTSecret = redacted
+	}
+	keys := make([]string, len(c.Auth.APIKeys))
+	for i := range keys {
+		keys[i] = redacted
+	}
+	c.Auth.APIKeys = keys
+	return c
+}
+
+func (c Config) YAML() ([]byte, error) {
+	return yaml.Marshal(c.Redacted())
+}
@@ -7,13 +7,30 @@ import (
 	"go-backend/internal/models"
 )
 
+func Models() []any {
+	return []any{&models.Device{}, &models.Tag{}, &models.DeviceTag{}, &models.CategorySchema{}, &models.Brand{}, &models.BrandAlias{}, &models.Reservation{}, &models.Lease{}, &models.WebhookSubscription{}, &models.OutboxEvent{}, &models.WebhookDelivery{}, &models.DeviceChange{}}
+}
+
 func Connect(path string) (*gorm.DB, error) {
 	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
-	if err := db.AutoMigrate(&models.Device{}); err != nil {
+	if err := db.AutoMigrate(Models()...); err != nil {
+		return nil, err
+	}
+	// Devices created before updated_at existed were last modified no later
+	// than their creation as far as we know.
+	if err := db.Exec("UPDATE devices SET updated_at = created_at WHERE updated_at IS NULL").Error; err != nil {
 		return nil, err
 	}
 	return db, nil
 }
+
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,6 @@
+package docs
+
+import "embed"
+
+//go:embed ui
+var UI embed.FS
@@ -0,0 +1,35 @@
+package dto
+
+import "go-backend/internal/models"
+
+type BrandIDParams struct {
+	ID int64 `uri:"id" binding:"required"`
+}
+
+type BrandRequest struct {
+	Name    string   `json:"name" binding:"required"`
+	Aliases []string `json:"aliases"`
+}
+
+type BrandResponse struct {
+	ID      int64    `json:"id"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+	Devices int64    `json:"devices"`
+}
+
+func FromBrand(b *models.BrandWithAliases) BrandResponse {
+	aliases := b.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+	return BrandResponse{ID: b.ID, Name: b.Name, Aliases: aliases, Devices: b.Devices}
+}
+
+func FromBrands(list []models.BrandWithAliases) []BrandResponse {
+	out := make([]BrandResponse, 0, len(list))
+	for i := range list {
+		out = append(out, FromBrand(&list[i]))
+	}
+	return out
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "go-backend/internal/cache"
+
+type CacheStatsResponse struct {
+	Enabled     bool    `json:"enabled"`
+	Hits        uint64  `json:"hits"`
+	Misses      uint64  `json:"misses"`
+	HitRatio    float64 `json:"hit_ratio"`
+	Evictions   uint64  `json:"evictions"`
+	Expirations uint64  `json:"expirations"`
+	Entries     int     `json:"entries"`
+}
+
+func FromCacheStats(s cache.Stats, enabled bool) CacheStatsResponse {
+	res := CacheStatsResponse{
+		Enabled: enabled, Hits: s.Hits, Misses: s.Misses,
+		Evictions: s.Evictions, Expirations: s.Expirations, Entries: s.Entries,
+	}
+	if total := s.Hits + s.Misses; total > 0 {
+		res.HitRatio = float64(s.Hits) / float64(total)
+	}
+	return res
+}
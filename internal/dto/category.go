@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"encoding/json"
+	"go-backend/internal/models"
+	"time"
+)
+
+type CategoryParams struct {
+	Category string `uri:"category" binding:"required"`
+}
+
+type CategorySchemaResponse struct {
+	Category  string         `json:"category"`
+	Schema    map[string]any `json:"schema"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+func FromCategorySchema(s *models.CategorySchema) CategorySchemaResponse {
+	res := CategorySchemaResponse{Category: s.Category, UpdatedAt: s.UpdatedAt}
+	_ = json.Unmarshal([]byte(s.Schema), &res.Schema)
+	return res
+}
+
+func FromCategorySchemas(list []models.CategorySchema) []CategorySchemaResponse {
+	out := make([]CategorySchemaResponse, 0, len(list))
+	for i := range list {
+		out = append(out, FromCategorySchema(&list[i]))
+	}
+	return out
+}
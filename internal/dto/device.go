@@ -1,22 +1,120 @@
 package dto
 
-import "time"
-
+// Attributes of a device with a category are validated against the JSON
+// Schema registered for it. Serial numbers and asset tags are trimmed and
+// must be unique; blank ones are left out.
 type CreateDeviceRequest struct {
-	Name  string `json:"name" binding:"required"`
-	Brand string `json:"brand" binding:"required"`
-	State string `json:"state" binding:"required,oneof=available in-use inactive"`
+	Name         string         `json:"name" binding:"required"`
+	Brand        string         `json:"brand" binding:"required"`
+	State        string         `json:"state" binding:"required,oneof=available in-use inactive"`
+	SerialNumber *string        `json:"serial_number" binding:"omitempty,max=128"`
+	AssetTag     *string        `json:"asset_tag" binding:"omitempty,max=128"`
+	Category     string         `json:"category"`
+	Attributes   map[string]any `json:"attributes"`
 }
 
 type UpdateDeviceRequest struct {
-	Name      string     `json:"name" binding:"required"`
-	Brand     string     `json:"brand" binding:"required"`
-	State     string     `json:"state" binding:"required,oneof=available in-use inactive"`
-	CreatedAt *time.Time `json:"created_at"`
+	Name         string         `json:"name" binding:"required"`
+	Brand        string         `json:"brand" binding:"required"`
+	State        string         `json:"state" binding:"required,oneof=available in-use inactive"`
+	SerialNumber *string        `json:"serial_number" binding:"omitempty,max=128"`
+	AssetTag     *string        `json:"asset_tag" binding:"omitempty,max=128"`
+	Category     string         `json:"category"`
+	Attributes   map[string]any `json:"attributes"`
+	CreatedAt    *Timestamp     `json:"created_at"`
 }
 
+// PatchDeviceRequest replaces the attributes as a whole; use a merge patch
+// to change single attributes. An empty serial_number or asset_tag removes it.
 type PatchDeviceRequest struct {
-	Name  *string `json:"name" binding:"omitempty"`
-	Brand *string `json:"brand" binding:"omitempty"`
-	State *string `json:"state" binding:"omitempty,oneof=available in-use inactive"`
+	Name         *string        `json:"name" binding:"omitempty"`
+	Brand        *string        `json:"brand" binding:"omitempty"`
+	State        *string        `json:"state" binding:"omitempty,oneof=available in-use inactive"`
+	SerialNumber *string        `json:"serial_number" binding:"omitempty,max=128"`
+	AssetTag     *string        `json:"asset_tag" binding:"omitempty,max=128"`
+	Category     *string        `json:"category"`
+	Attributes   map[string]any `json:"attributes"`
+}
+
+// DeviceMergePatch documents application/merge-patch+json (RFC 7396) bodies.
+// A null member removes it, which the required device fields reject.
+type DeviceMergePatch struct {
+	Name         *string         `json:"name"`
+	Brand        *string         `json:"brand"`
+	State        *string         `json:"state" binding:"omitempty,oneof=available in-use inactive"`
+	SerialNumber *string         `json:"serial_number"`
+	AssetTag     *string         `json:"asset_tag"`
+	Category     *string         `json:"category"`
+	Attributes   *map[string]any `json:"attributes"`
+	CreatedAt    *Timestamp      `json:"created_at"`
+}
+
+// JSONPatchOperation is one operation of an application/json-patch+json
+// (RFC 6902) body. Paths address the device representation, e.g. /state.
+type JSONPatchOperation struct {
+	Op    string `json:"op" binding:"required,oneof=add remove replace move copy test"`
+	Path  string `json:"path" binding:"required"`
+	From  string `json:"from"`
+	Value any    `json:"value"`
+}
+
+type DeviceIDParams struct {
+	ID int64 `uri:"id" binding:"required"`
+}
+
+type DeviceSerialParams struct {
+	Serial string `uri:"serial" binding:"required"`
+	TimeFormatParams
+}
+
+type DeviceAssetTagParams struct {
+	AssetTag string `uri:"asset_tag" binding:"required"`
+	TimeFormatParams
+}
+
+type ListDevicesQuery struct {
+	Brand    string `form:"brand"`
+	State    string `form:"state" binding:"omitempty,oneof=available in-use inactive"`
+	Category string `form:"category"`
+	Tag      string `form:"tag"`
+	TagMatch string `form:"tag_match" binding:"omitempty,oneof=any all"`
+	FieldsParams
+	TimeFormatParams
+	ConditionalParams
+}
+
+type GetDeviceParams struct {
+	DeviceIDParams
+	FieldsParams
+	TimeFormatParams
+	ConditionalParams
+}
+
+// ConditionalParams documents conditional GET; the handlers read the
+// headers directly.
+type ConditionalParams struct {
+	IfNoneMatch     string `header:"If-None-Match" form:"-"`
+	IfModifiedSince string `header:"If-Modified-Since" form:"-"`
+}
+
+// DuplicateDetails names the device that already has a serial number or
+// asset tag.
+type DuplicateDetails struct {
+	Field      string `json:"field"`
+	ExistingID int64  `json:"existing_id"`
+}
+
+type DeviceLabelParams struct {
+	DeviceIDParams
+	Format string `form:"format" binding:"omitempty,oneof=png svg"`
+}
+
+// DeviceLabelsQuery selects the devices of a label sheet like
+// ListDevicesQuery.
+type DeviceLabelsQuery struct {
+	Brand    string `form:"brand"`
+	State    string `form:"state" binding:"omitempty,oneof=available in-use inactive"`
+	Category string `form:"category"`
+	Tag      string `form:"tag"`
+	TagMatch string `form:"tag_match" binding:"omitempty,oneof=any all"`
 }
@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"go-backend/internal/events"
+	"go-backend/internal/models"
+)
+
+type DeviceEventsQuery struct {
+	Brand       string `form:"brand"`
+	State       string `form:"state" binding:"omitempty,oneof=available in-use inactive"`
+	LastEventID string `form:"last_event_id"`
+	// Documented for the spec; the handler reads the header directly.
+	LastEventIDHeader string `header:"Last-Event-ID" form:"-"`
+	TimeFormatParams
+}
+
+type DeviceEvent struct {
+	ID         string         `json:"id"`
+	Type       string         `json:"type" binding:"oneof=created updated patched deleted"`
+	Device     DeviceResponse `json:"device"`
+	OccurredAt Timestamp      `json:"occurred_at"`
+}
+
+func FromEvent(ev events.Event, tf models.TimeFormat) DeviceEvent {
+	return DeviceEvent{ID: ev.ID, Type: string(ev.Type), Device: FromModel(&ev.Device, tf), OccurredAt: NewTimestamp(ev.OccurredAt, tf)}
+}
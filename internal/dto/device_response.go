@@ -5,27 +5,75 @@ import (
 )
 
 type DeviceResponse struct {
-	ID        int64  `json:"id"`
-	Name      string `json:"name"`
-	Brand     string `json:"brand"`
-	State     string `json:"state"`
-	CreatedAt string `json:"created_at"`
+	ID           int64          `json:"id"`
+	Name         string         `json:"name"`
+	Brand        string         `json:"brand"`
+	BrandID      *int64         `json:"brand_id"`
+	State        string         `json:"state"`
+	SerialNumber *string        `json:"serial_number"`
+	AssetTag     *string        `json:"asset_tag"`
+	Category     string         `json:"category"`
+	Attributes   map[string]any `json:"attributes"`
+	CreatedAt    Timestamp      `json:"created_at"`
+	UpdatedAt    Timestamp      `json:"updated_at"`
 }
 
-func FromModel(d *models.Device) DeviceResponse {
+func FromModel(d *models.Device, tf models.TimeFormat) DeviceResponse {
+	attrs := d.Attributes
+	if attrs == nil {
+		attrs = models.Attributes{}
+	}
 	return DeviceResponse{
-		ID:        d.ID,
-		Name:      d.Name,
-		Brand:     d.Brand,
-		State:     string(d.State),
-		CreatedAt: d.CreatedAt.Time.UTC().Format(models.DbTimeLayout),
+		ID:           d.ID,
+		Name:         d.Name,
+		Brand:        d.Brand,
+		BrandID:      d.BrandID,
+		State:        string(d.State),
+		SerialNumber: d.SerialNumber,
+		AssetTag:     d.AssetTag,
+		Category:     d.Category,
+		Attributes:   attrs,
+		CreatedAt:    NewTimestamp(d.CreatedAt.Time, tf),
+		UpdatedAt:    NewTimestamp(d.UpdatedAt.Time, tf),
 	}
 }
 
-func FromModels(list []models.Device) []DeviceResponse {
+func FromModels(list []models.Device, tf models.TimeFormat) []DeviceResponse {
 	out := make([]DeviceResponse, 0, len(list))
 	for i := range list {
-		out = append(out, FromModel(&list[i]))
+		out = append(out, FromModel(&list[i], tf))
 	}
 	return out
 }
+
+// DeviceMapper renders devices in the response shape of one API version, so
+// a version can change the wire format without forking the handlers.
+type DeviceMapper interface {
+	Device(d *models.Device, tf models.TimeFormat) any
+	Devices(list []models.Device, tf models.TimeFormat) any
+	// Columns maps each response field to the device column it is read from.
+	Columns() map[string]string
+}
+
+// V1 renders devices as DeviceResponse.
+type V1 struct{}
+
+var v1Columns = map[string]string{
+	"id":            "id",
+	"name":          "name",
+	"brand":         "brand",
+	"brand_id":      "brand_id",
+	"state":         "state",
+	"serial_number": "serial_number",
+	"asset_tag":     "asset_tag",
+	"category":      "category",
+	"attributes":    "attributes",
+	"created_at":    "created_at",
+	"updated_at":    "updated_at",
+}
+
+func (V1) Device(d *models.Device, tf models.TimeFormat) any { return FromModel(d, tf) }
+
+func (V1) Devices(list []models.Device, tf models.TimeFormat) any { return FromModels(list, tf) }
+
+func (V1) Columns() map[string]string { return v1Columns }
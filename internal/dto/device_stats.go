@@ -0,0 +1,44 @@
+package dto
+
+import "go-backend/internal/models"
+
+type DeviceStatsQuery struct {
+	GroupBy  string `form:"group_by"`
+	Interval string `form:"interval" binding:"omitempty,oneof=day week month"`
+	Brand    string `form:"brand"`
+	State    string `form:"state" binding:"omitempty,oneof=available in-use inactive"`
+	From     string `form:"from" format:"date"`
+	To       string `form:"to" format:"date"`
+}
+
+// DeviceStatsBucket is one group; only the grouped dimensions are set.
+type DeviceStatsBucket struct {
+	Brand  *string `json:"brand,omitempty"`
+	State  *string `json:"state,omitempty"`
+	Period *string `json:"period,omitempty"`
+	Count  int64   `json:"count"`
+}
+
+type DeviceStatsResponse struct {
+	Total   int64               `json:"total"`
+	Buckets []DeviceStatsBucket `json:"buckets"`
+}
+
+func FromDeviceCounts(q models.StatsQuery, counts []models.DeviceCount) DeviceStatsResponse {
+	res := DeviceStatsResponse{Buckets: make([]DeviceStatsBucket, 0, len(counts))}
+	for _, c := range counts {
+		b := DeviceStatsBucket{Count: c.Count}
+		if q.ByBrand {
+			b.Brand = &c.Brand
+		}
+		if q.ByState {
+			b.State = &c.State
+		}
+		if q.Interval != "" {
+			b.Period = &c.Period
+		}
+		res.Total += c.Count
+		res.Buckets = append(res.Buckets, b)
+	}
+	return res
+}
@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FieldsParams trims responses to a sparse fieldset, e.g. fields=id,name,state.
+type FieldsParams struct {
+	Fields string `form:"fields"`
+}
+
+// SelectFields validates a comma separated fields parameter against the
+// response fields of a mapper and returns them with the columns to read.
+// An empty parameter selects every field and column.
+func SelectFields(raw string, columns map[string]string) (fields, cols []string, err error) {
+	if raw == "" {
+		return nil, nil, nil
+	}
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		col, ok := columns[f]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown field %q", f)
+		}
+		if !slices.Contains(fields, f) {
+			fields = append(fields, f)
+			cols = append(cols, col)
+		}
+	}
+	return fields, cols, nil
+}
+
+// Sparse renders only fields of v, a response object or a list of them.
+func Sparse(v any, fields []string) any {
+	if len(fields) == 0 {
+		return v
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var list []map[string]json.RawMessage
+	if json.Unmarshal(b, &list) == nil {
+		for i := range list {
+			list[i] = pick(list[i], fields)
+		}
+		return list
+	}
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(b, &obj) == nil {
+		return pick(obj, fields)
+	}
+	return v
+}
+
+func pick(obj map[string]json.RawMessage, fields []string) map[string]json.RawMessage {
+	out := make(map[string]json.RawMessage, len(fields))
+	for _, f := range fields {
+		if v, ok := obj[f]; ok {
+			out[f] = v
+		}
+	}
+	return out
+}
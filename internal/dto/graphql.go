@@ -0,0 +1,28 @@
+package dto
+
+// GraphQLRequest follows the GraphQL over HTTP convention; clients commonly
+// send "variables": null, hence the pointer.
+type GraphQLRequest struct {
+	Query         string          `json:"query" binding:"required"`
+	OperationName string          `json:"operationName"`
+	Variables     *map[string]any `json:"variables"`
+}
+
+type GraphQLErrorLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Locations  []GraphQLErrorLocation `json:"locations,omitempty"`
+	Path       []any                  `json:"path,omitempty"`
+	Extensions map[string]any         `json:"extensions,omitempty"`
+}
+
+// GraphQLResponse documents the response envelope; data is null when the
+// request failed validation.
+type GraphQLResponse struct {
+	Data   any            `json:"data,omitempty"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"go-backend/internal/models"
+	"time"
+)
+
+// AcquireLeaseRequest checks a device out to Holder for TTLSeconds, or for
+// leases.default_ttl when it is omitted.
+type AcquireLeaseRequest struct {
+	Holder     string `json:"holder" binding:"required"`
+	TTLSeconds int64  `json:"ttl_seconds" binding:"omitempty,min=1"`
+}
+
+// RenewLeaseRequest makes a lease expire TTLSeconds, or leases.default_ttl,
+// from now.
+type RenewLeaseRequest struct {
+	TTLSeconds int64 `json:"ttl_seconds" binding:"omitempty,min=1"`
+}
+
+type LeaseIDParams struct {
+	ID int64 `uri:"id" binding:"required"`
+}
+
+type LeaseResponse struct {
+	ID        int64      `json:"id"`
+	DeviceID  int64      `json:"device_id"`
+	Holder    string     `json:"holder"`
+	Status    string     `json:"status" binding:"oneof=active released expired"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+}
+
+func FromLease(l *models.Lease) LeaseResponse {
+	return LeaseResponse{
+		ID:        l.ID,
+		DeviceID:  l.DeviceID,
+		Holder:    l.Holder,
+		Status:    string(l.Status),
+		ExpiresAt: l.ExpiresAt,
+		CreatedAt: l.CreatedAt,
+		EndedAt:   l.EndedAt,
+	}
+}
@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"go-backend/internal/models"
+	"time"
+)
+
+type CreateReservationRequest struct {
+	Holder   string    `json:"holder" binding:"required"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+}
+
+type ReservationIDParams struct {
+	ID int64 `uri:"id" binding:"required"`
+}
+
+// AvailableDevicesQuery selects devices that can be booked from from until
+// to, both RFC 3339.
+type AvailableDevicesQuery struct {
+	From  time.Time `form:"from" binding:"required"`
+	To    time.Time `form:"to" binding:"required"`
+	Brand string    `form:"brand"`
+	TimeFormatParams
+}
+
+type ReservationResponse struct {
+	ID         int64     `json:"id"`
+	DeviceID   int64     `json:"device_id"`
+	Holder     string    `json:"holder"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	Status     string    `json:"status" binding:"oneof=scheduled active completed cancelled"`
+	CheckedOut bool      `json:"checked_out"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func FromReservation(r *models.Reservation) ReservationResponse {
+	return ReservationResponse{
+		ID:         r.ID,
+		DeviceID:   r.DeviceID,
+		Holder:     r.Holder,
+		StartsAt:   r.StartsAt,
+		EndsAt:     r.EndsAt,
+		Status:     string(r.Status),
+		CheckedOut: r.CheckedOut,
+		CreatedAt:  r.CreatedAt,
+	}
+}
+
+func FromReservations(list []models.Reservation) []ReservationResponse {
+	out := make([]ReservationResponse, 0, len(list))
+	for i := range list {
+		out = append(out, FromReservation(&list[i]))
+	}
+	return out
+}
+
+// ReservationConflictDetails names a reservation overlapping the requested
+// window.
+type ReservationConflictDetails struct {
+	ReservationID int64 `json:"reservation_id"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "go-backend/internal/models"
+
+type DeviceTagParams struct {
+	DeviceIDParams
+	Tag string `uri:"tag" binding:"required"`
+}
+
+type DeviceTagsResponse struct {
+	Tags []string `json:"tags"`
+}
+
+type TagResponse struct {
+	Name    string `json:"name"`
+	Devices int64  `json:"devices"`
+}
+
+func FromTagCounts(counts []models.TagCount) []TagResponse {
+	out := make([]TagResponse, 0, len(counts))
+	for _, c := range counts {
+		out = append(out, TagResponse{Name: c.Name, Devices: c.Devices})
+	}
+	return out
+}
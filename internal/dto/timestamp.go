@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"go-backend/internal/models"
+	"go-backend/internal/openapi"
+)
+
+// Timestamp renders a time in Format and decodes from any format the API
+// renders, so clients can send back what they received.
+type Timestamp struct {
+	time.Time
+	Format models.TimeFormat
+}
+
+func NewTimestamp(t time.Time, f models.TimeFormat) Timestamp {
+	return Timestamp{Time: t.UTC(), Format: f}
+}
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	switch t.Format {
+	case models.TimeEpoch:
+		return strconv.AppendInt(nil, t.Unix(), 10), nil
+	case models.TimeRFC3339:
+		return json.Marshal(t.UTC().Format(time.RFC3339))
+	default:
+		return json.Marshal(t.UTC().Format(models.DbTimeLayout))
+	}
+}
+
+func (t *Timestamp) UnmarshalJSON(b []byte) error {
+	var raw any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	s, ok := raw.(string)
+	if !ok {
+		s = string(b)
+	}
+	parsed, err := models.ParseTime(s)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+func (Timestamp) OpenAPISchema() *openapi.Schema {
+	return &openapi.Schema{
+		Description: "RFC 3339 or DD.MM.YYYY HH:mm:ss string, or Unix seconds, as selected by time_format",
+		OneOf:       []*openapi.Schema{{Type: "string"}, {Type: "integer", Format: "int64"}},
+	}
+}
+
+// TimeFormatParams selects how response timestamps are rendered; the query
+// parameter wins over the header.
+type TimeFormatParams struct {
+	TimeFormat string `form:"time_format" binding:"omitempty,oneof=rfc3339 epoch legacy"`
+	// Documented for the spec; the handler reads the header directly.
+	TimeFormatHeader string `header:"X-Time-Format" form:"-" binding:"omitempty,oneof=rfc3339 epoch legacy"`
+}
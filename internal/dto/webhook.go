@@ -0,0 +1,101 @@
+package dto
+
+import (
+	"time"
+
+	"go-backend/internal/models"
+)
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+	Active     *bool    `json:"active"`
+}
+
+type UpdateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"event_types"`
+	Active     *bool    `json:"active"`
+}
+
+type WebhookIDParams struct {
+	ID int64 `uri:"id" binding:"required"`
+}
+
+type WebhookDeliveriesQuery struct {
+	ID     int64  `uri:"id"`
+	Status string `form:"status" binding:"omitempty,oneof=pending succeeded dead"`
+}
+
+type WebhookResponse struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             int64      `json:"id"`
+	SubscriptionID int64      `json:"subscription_id"`
+	EventID        int64      `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status" binding:"oneof=pending succeeded dead"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// WebhookPayload is the body POSTed to subscribers.
+type WebhookPayload struct {
+	EventID    int64          `json:"event_id"`
+	Type       string         `json:"type"`
+	OccurredAt Timestamp      `json:"occurred_at"`
+	Device     DeviceResponse `json:"device"`
+}
+
+// FromWebhook omits the secret; set it explicitly where it may be revealed.
+func FromWebhook(s *models.WebhookSubscription) WebhookResponse {
+	return WebhookResponse{
+		ID:         s.ID,
+		URL:        s.URL,
+		EventTypes: s.Types(),
+		Active:     s.Active,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+func FromWebhooks(list []models.WebhookSubscription) []WebhookResponse {
+	out := make([]WebhookResponse, 0, len(list))
+	for i := range list {
+		out = append(out, FromWebhook(&list[i]))
+	}
+	return out
+}
+
+func FromDeliveries(list []models.WebhookDelivery) []WebhookDeliveryResponse {
+	out := make([]WebhookDeliveryResponse, 0, len(list))
+	for _, d := range list {
+		out = append(out, WebhookDeliveryResponse{
+			ID:             d.ID,
+			SubscriptionID: d.SubscriptionID,
+			EventID:        d.OutboxEventID,
+			EventType:      d.EventType,
+			Status:         string(d.Status),
+			Attempts:       d.Attempts,
+			NextAttemptAt:  d.NextAttemptAt,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			DeliveredAt:    d.DeliveredAt,
+			CreatedAt:      d.CreatedAt,
+		})
+	}
+	return out
+}
@@ -0,0 +1,38 @@
+package dto
+
+const (
+	WSSubscribe   = "subscribe"
+	WSUnsubscribe = "unsubscribe"
+	WSCommand     = "command"
+	WSEvent       = "event"
+	WSAck         = "ack"
+	WSError       = "error"
+
+	WSCheckOut = "checkout"
+	WSCheckIn  = "checkin"
+)
+
+// WSClientMessage is sent by clients over /devices/ws.
+type WSClientMessage struct {
+	Type      string  `json:"type" binding:"required,oneof=subscribe unsubscribe command"`
+	ID        string  `json:"id,omitempty"`
+	DeviceIDs []int64 `json:"device_ids,omitempty"`
+	Command   string  `json:"command,omitempty" binding:"omitempty,oneof=checkout checkin"`
+	DeviceID  int64   `json:"device_id,omitempty"`
+}
+
+// WSServerMessage is sent by the server over /devices/ws. ID echoes the
+// client message it answers.
+type WSServerMessage struct {
+	Type          string          `json:"type" binding:"oneof=event ack error"`
+	ID            string          `json:"id,omitempty"`
+	Event         *DeviceEvent    `json:"event,omitempty"`
+	Device        *DeviceResponse `json:"device,omitempty"`
+	Subscriptions []int64         `json:"subscriptions,omitempty"`
+	Error         *WSErrorBody    `json:"error,omitempty"`
+}
+
+type WSErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,158 @@
+package events
+
+import (
+	"crypto/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-backend/internal/models"
+)
+
+type Type string
+
+const (
+	Created Type = "created"
+	Updated Type = "updated"
+	Patched Type = "patched"
+	Deleted Type = "deleted"
+	// StateChanged accompanies an update or patch that changed the device
+	// state; it is only emitted through the webhook outbox.
+	StateChanged Type = "state_changed"
+	// LeaseExpired records that the lease reaper returned a device whose
+	// lease ran out; like StateChanged it only goes through the outbox.
+	LeaseExpired Type = "lease_expired"
+)
+
+// Types lists every event type webhooks can subscribe to.
+var Types = []Type{Created, Updated, Patched, Deleted, StateChanged, LeaseExpired}
+
+const DefaultReplaySize = 1024
+
+type Event struct {
+	// ID is "<epoch>-<sequence>". The epoch identifies the broker, which is
+	// new with every process, so a position from a restarted or different
+	// instance is never mistaken for one in this broker's sequence.
+	ID         string
+	seq        uint64
+	Type       Type
+	Device     models.Device
+	OccurredAt time.Time
+}
+
+type Filter func(Event) bool
+
+// Broker fans device change events out to subscribers and keeps the most
+// recent ones in a bounded buffer so clients can resume after reconnecting.
+type Broker struct {
+	mu     sync.Mutex
+	epoch  string
+	nextID uint64
+	replay []Event
+	size   int
+	subs   map[*Subscription]struct{}
+}
+
+func NewBroker(replaySize int) *Broker {
+	if replaySize <= 0 {
+		replaySize = DefaultReplaySize
+	}
+	return &Broker{epoch: rand.Text()[:10], nextID: 1, size: replaySize, subs: map[*Subscription]struct{}{}}
+}
+
+type Subscription struct {
+	C       <-chan Event
+	ch      chan Event
+	filter  Filter
+	broker  *Broker
+	dropped bool
+}
+
+// Publish records an event and delivers it to every matching subscriber.
+// Subscribers whose buffer is full are considered slow and disconnected.
+func (b *Broker) Publish(t Type, d models.Device) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ev := Event{ID: b.epoch + "-" + strconv.FormatUint(b.nextID, 10), seq: b.nextID, Type: t, Device: d, OccurredAt: time.Now().UTC()}
+	b.nextID++
+	if len(b.replay) == b.size {
+		b.replay = append(b.replay[:0], b.replay[1:]...)
+	}
+	b.replay = append(b.replay, ev)
+	for s := range b.subs {
+		if s.filter != nil && !s.filter(ev) {
+			continue
+		}
+		select {
+		case s.ch <- ev:
+		default:
+			s.dropped = true
+			b.remove(s)
+		}
+	}
+	return ev
+}
+
+// Subscribe registers a subscriber with a delivery buffer of the given size.
+// It returns the buffered events after lastID that match the filter, and
+// whether the replay is complete. It is not when lastID has already been
+// evicted, or comes from another epoch or is malformed; nothing is replayed
+// then, as the broker cannot tell which of its events the client has seen.
+func (b *Broker) Subscribe(lastID string, buffer int, filter Filter) (*Subscription, []Event, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan Event, buffer)
+	s := &Subscription{C: ch, ch: ch, filter: filter, broker: b}
+	b.subs[s] = struct{}{}
+	if lastID == "" {
+		return s, nil, true
+	}
+	seq, ok := b.sequence(lastID)
+	if !ok {
+		return s, nil, false
+	}
+	complete := len(b.replay) == 0 || seq+1 >= b.replay[0].seq
+	var out []Event
+	for _, ev := range b.replay {
+		if ev.seq > seq && (filter == nil || filter(ev)) {
+			out = append(out, ev)
+		}
+	}
+	return s, out, complete
+}
+
+// sequence returns the position of id in this broker's sequence, if id was
+// issued by it.
+func (b *Broker) sequence(id string) (uint64, bool) {
+	epoch, n, ok := strings.Cut(id, "-")
+	if !ok || epoch != b.epoch {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(n, 10, 64)
+	if err != nil || seq >= b.nextID {
+		return 0, false
+	}
+	return seq, true
+}
+
+// Close unsubscribes; it is safe to call more than once.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s)
+}
+
+// Dropped reports whether the broker disconnected the subscriber for falling behind.
+func (s *Subscription) Dropped() bool {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return s.dropped
+}
+
+func (b *Broker) remove(s *Subscription) {
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.ch)
+	}
+}
@@ -0,0 +1,55 @@
+package grpcapi
+
+import (
+	"go-backend/internal/events"
+	"go-backend/internal/models"
+	devicesv1 "go-backend/pkg/pb/devices/v1"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var states = map[devicesv1.State]models.State{
+	devicesv1.State_STATE_AVAILABLE: models.StateAvailable,
+	devicesv1.State_STATE_IN_USE:    models.StateInUse,
+	devicesv1.State_STATE_INACTIVE:  models.StateInactive,
+}
+
+var eventTypes = map[events.Type]devicesv1.DeviceEvent_Type{
+	events.Created: devicesv1.DeviceEvent_TYPE_CREATED,
+	events.Updated: devicesv1.DeviceEvent_TYPE_UPDATED,
+	events.Patched: devicesv1.DeviceEvent_TYPE_PATCHED,
+	events.Deleted: devicesv1.DeviceEvent_TYPE_DELETED,
+}
+
+func fromState(s devicesv1.State) (models.State, bool) {
+	st, ok := states[s]
+	return st, ok
+}
+
+func toState(s models.State) devicesv1.State {
+	for k, v := range states {
+		if v == s {
+			return k
+		}
+	}
+	return devicesv1.State_STATE_UNSPECIFIED
+}
+
+func toDevice(d *models.Device) *devicesv1.Device {
+	return &devicesv1.Device{
+		Id:        d.ID,
+		Name:      d.Name,
+		Brand:     d.Brand,
+		State:     toState(d.State),
+		CreatedAt: timestamppb.New(d.CreatedAt.Time),
+	}
+}
+
+func toEvent(ev events.Event) *devicesv1.DeviceEvent {
+	return &devicesv1.DeviceEvent{
+		Id:         ev.ID,
+		Type:       eventTypes[ev.Type],
+		Device:     toDevice(&ev.Device),
+		OccurredAt: timestamppb.New(ev.OccurredAt),
+	}
+}
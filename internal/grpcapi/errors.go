@@ -0,0 +1,72 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"go-backend/internal/models"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is set on the ErrorInfo detail of every error status; its
+// reason carries the same code the REST API returns in ErrorPayload.code.
+const ErrorDomain = "go-backend"
+
+func toStatus(err error) error {
+	code, reason := errorCode(err)
+	return withReason(code, reason, err.Error())
+}
+
+func errorCode(err error) (codes.Code, string) {
+	var (
+		dup   *models.DuplicateError
+		attrs *models.AttributesError
+	)
+	switch {
+	case errors.As(err, &dup):
+		return codes.AlreadyExists, "duplicate_device"
+	case errors.As(err, &attrs):
+		return codes.InvalidArgument, "invalid_attributes"
+	case errors.Is(err, models.ErrInvalidIdentifier):
+		return codes.InvalidArgument, "invalid_identifier"
+	case errors.Is(err, models.ErrConcurrentModification):
+		return codes.Aborted, "concurrent_modification"
+	case errors.Is(err, models.ErrDeviceNotFound):
+		return codes.NotFound, "not_found"
+	case errors.Is(err, models.ErrCannotDeleteInUse):
+		return codes.FailedPrecondition, "in_use_delete_blocked"
+	case errors.Is(err, models.ErrNotAvailable):
+		return codes.FailedPrecondition, "device_not_available"
+	case errors.Is(err, models.ErrNotInUse):
+		return codes.FailedPrecondition, "device_not_in_use"
+	case errors.Is(err, models.ErrCannotUpdateCreated):
+		return codes.FailedPrecondition, "cannot_update_created_at"
+	case errors.Is(err, models.ErrCannotUpdateFields):
+		return codes.FailedPrecondition, "cannot_update_name_brand_in_use"
+	case errors.Is(err, models.ErrInvalidState):
+		return codes.InvalidArgument, "invalid_state"
+	case errors.Is(err, models.ErrUnknownBrand):
+		return codes.FailedPrecondition, "unknown_brand"
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled, "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded, "deadline_exceeded"
+	default:
+		return codes.Internal, "internal_error"
+	}
+}
+
+func invalidArgument(msg string) error {
+	return withReason(codes.InvalidArgument, "validation_error", msg)
+}
+
+func withReason(code codes.Code, reason, msg string) error {
+	st := status.New(code, msg)
+	if withInfo, err := st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: ErrorDomain}); err == nil {
+		st = withInfo
+	}
+	return st.Err()
+}
@@ -0,0 +1,29 @@
+package grpcapi
+
+import (
+	"fmt"
+	"testing"
+
+	"go-backend/internal/models"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestErrorCode(t *testing.T) {
+	cases := []struct {
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		{&models.DuplicateError{Field: "serial_number", DeviceID: 1}, codes.AlreadyExists, "duplicate_device"},
+		{&models.AttributesError{Category: "laptop"}, codes.InvalidArgument, "invalid_attributes"},
+		{models.ErrInvalidIdentifier, codes.InvalidArgument, "invalid_identifier"},
+		{fmt.Errorf("patch: %w", models.ErrConcurrentModification), codes.Aborted, "concurrent_modification"},
+		{models.ErrDeviceNotFound, codes.NotFound, "not_found"},
+	}
+	for _, c := range cases {
+		if code, reason := errorCode(c.err); code != c.code || reason != c.reason {
+			t.Errorf("%v: got %s %s, want %s %s", c.err, code, reason, c.code, c.reason)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package grpcapi
+
+import (
+	"context"
+	"slices"
+
+	"go-backend/internal/events"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	devicesv1 "go-backend/pkg/pb/devices/v1"
+	"go-backend/pkg/utils"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	defaultPageSize  = 50
+	maxPageSize      = 500
+	watchClientQueue = 64
+)
+
+type DeviceServer struct {
+	devicesv1.UnimplementedDeviceServiceServer
+	svc *services.DeviceService
+}
+
+func NewDeviceServer(s *services.DeviceService) *DeviceServer { return &DeviceServer{svc: s} }
+
+// New returns a gRPC server exposing the device service together with the
+// standard health and reflection services. The health server reports SERVING
+// until the caller marks it otherwise during shutdown.
+func New(s *services.DeviceService, opts ...grpc.ServerOption) (*grpc.Server, *health.Server) {
+	srv := grpc.NewServer(opts...)
+	devicesv1.RegisterDeviceServiceServer(srv, NewDeviceServer(s))
+	hs := health.NewServer()
+	hs.SetServingStatus(devicesv1.DeviceService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+	reflection.Register(srv)
+	return srv, hs
+}
+
+func (s *DeviceServer) CreateDevice(ctx context.Context, req *devicesv1.CreateDeviceRequest) (*devicesv1.Device, error) {
+	if req.GetName() == "" || req.GetBrand() == "" {
+		return nil, invalidArgument("name and brand are required")
+	}
+	state, ok := fromState(req.GetState())
+	if !ok {
+		return nil, invalidArgument("state is required")
+	}
+	d := models.Device{Name: req.GetName(), Brand: req.GetBrand(), State: state, CreatedAt: models.NowFormattedTime()}
+	if _, err := s.svc.Create(ctx, &d); err != nil {
+		return nil, toStatus(err)
+	}
+	return toDevice(&d), nil
+}
+
+func (s *DeviceServer) GetDevice(ctx context.Context, req *devicesv1.GetDeviceRequest) (*devicesv1.Device, error) {
+	d, err := s.svc.Get(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toDevice(d), nil
+}
+
+func (s *DeviceServer) ListDevices(ctx context.Context, req *devicesv1.ListDevicesRequest) (*devicesv1.ListDevicesResponse, error) {
+	size := int(req.GetPageSize())
+	switch {
+	case size < 0:
+		return nil, invalidArgument("page_size must not be negative")
+	case size == 0:
+		size = defaultPageSize
+	case size > maxPageSize:
+		size = maxPageSize
+	}
+	after, err := utils.DecodeCursor(req.GetPageToken())
+	if err != nil {
+		return nil, invalidArgument("invalid page_token")
+	}
+	state := ""
+	if req.GetState() != devicesv1.State_STATE_UNSPECIFIED {
+		st, ok := fromState(req.GetState())
+		if !ok {
+			return nil, invalidArgument("invalid state")
+		}
+		state = string(st)
+	}
+	// Fetch one extra row to learn whether another page follows.
+	list, err := s.svc.ListPage(ctx, models.DeviceFilter{Brand: req.GetBrand(), State: state}, after, size+1)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &devicesv1.ListDevicesResponse{}
+	if len(list) > size {
+		list = list[:size]
+		resp.NextPageToken = utils.EncodeCursor(list[size-1].ID)
+	}
+	for i := range list {
+		resp.Devices = append(resp.Devices, toDevice(&list[i]))
+	}
+	return resp, nil
+}
+
+func (s *DeviceServer) UpdateDevice(ctx context.Context, req *devicesv1.UpdateDeviceRequest) (*devicesv1.Device, error) {
+	if req.GetName() == "" || req.GetBrand() == "" {
+		return nil, invalidArgument("name and brand are required")
+	}
+	state, ok := fromState(req.GetState())
+	if !ok {
+		return nil, invalidArgument("state is required")
+	}
+	ex, err := s.svc.Get(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	created := ex.CreatedAt
+	if req.GetCreatedAt() != nil {
+		created = models.NewFormattedTime(req.GetCreatedAt().AsTime())
+	}
+	// The API has no identifiers, category or attributes, so an update keeps them.
+	d := models.Device{
+		Name: req.GetName(), Brand: req.GetBrand(), State: state, SerialNumber: ex.SerialNumber, AssetTag: ex.AssetTag,
+		Category: ex.Category, Attributes: ex.Attributes, CreatedAt: created,
+	}
+	if err := s.svc.Update(ctx, req.GetId(), &d); err != nil {
+		return nil, toStatus(err)
+	}
+	return s.GetDevice(ctx, &devicesv1.GetDeviceRequest{Id: req.GetId()})
+}
+
+func (s *DeviceServer) PatchDevice(ctx context.Context, req *devicesv1.PatchDeviceRequest) (*devicesv1.Device, error) {
+	m := map[string]any{}
+	if req.Name != nil {
+		m["name"] = req.GetName()
+	}
+	if req.Brand != nil {
+		m["brand"] = req.GetBrand()
+	}
+	if req.State != nil {
+		state, ok := fromState(req.GetState())
+		if !ok {
+			return nil, invalidArgument("invalid state")
+		}
+		m["state"] = string(state)
+	}
+	if err := s.svc.Patch(ctx, req.GetId(), m); err != nil {
+		return nil, toStatus(err)
+	}
+	return s.GetDevice(ctx, &devicesv1.GetDeviceRequest{Id: req.GetId()})
+}
+
+func (s *DeviceServer) DeleteDevice(ctx context.Context, req *devicesv1.DeleteDeviceRequest) (*emptypb.Empty, error) {
+	if err := s.svc.Delete(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// Watch streams committed device changes. Like the SSE endpoint it replays
+// buffered events after last_event_id and sends TYPE_RESET when that position
+// is gone; streams that fall behind end with RESOURCE_EXHAUSTED.
+func (s *DeviceServer) Watch(req *devicesv1.WatchRequest, stream grpc.ServerStreamingServer[devicesv1.DeviceEvent]) error {
+	filter, err := watchFilter(req)
+	if err != nil {
+		return err
+	}
+	sub, replay, complete := s.svc.Events().Subscribe(req.GetLastEventId(), watchClientQueue, filter)
+	defer sub.Close()
+	// Flush headers so clients know the subscription is in place.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	if !complete {
+		if err := stream.Send(&devicesv1.DeviceEvent{Type: devicesv1.DeviceEvent_TYPE_RESET}); err != nil {
+			return err
+		}
+	}
+	for _, ev := range replay {
+		if err := stream.Send(toEvent(ev)); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case ev, ok := <-sub.C:
+			if !ok {
+				if sub.Dropped() {
+					return status.Error(codes.ResourceExhausted, "slow consumer")
+				}
+				return nil
+			}
+			if err := stream.Send(toEvent(ev)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func watchFilter(req *devicesv1.WatchRequest) (events.Filter, error) {
+	ids, brand := req.GetDeviceIds(), req.GetBrand()
+	var state models.State
+	if req.GetState() != devicesv1.State_STATE_UNSPECIFIED {
+		st, ok := fromState(req.GetState())
+		if !ok {
+			return nil, invalidArgument("invalid state")
+		}
+		state = st
+	}
+	if len(ids) == 0 && brand == "" && state == "" {
+		return nil, nil
+	}
+	return func(ev events.Event) bool {
+		return (len(ids) == 0 || slices.Contains(ids, ev.Device.ID)) &&
+			(brand == "" || ev.Device.Brand == brand) &&
+			(state == "" || ev.Device.State == state)
+	}, nil
+}
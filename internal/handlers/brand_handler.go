@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go-backend/internal/dto"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	apperror "go-backend/pkg/error"
+	"gorm.io/gorm"
+)
+
+type BrandHandler struct{ svc *services.BrandService }
+
+func NewBrandHandler(s *services.BrandService) *BrandHandler { return &BrandHandler{svc: s} }
+
+func (h *BrandHandler) Create(c *gin.Context) {
+	var req dto.BrandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid request payload", err.Error())
+		return
+	}
+	b, err := h.svc.Create(c, req.Name, req.Aliases)
+	if err != nil {
+		brandError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.FromBrand(b))
+}
+
+func (h *BrandHandler) List(c *gin.Context) {
+	list, err := h.svc.List(c)
+	if err != nil {
+		brandError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromBrands(list))
+}
+
+func (h *BrandHandler) Get(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	b, err := h.svc.Get(c, id)
+	if err != nil {
+		brandError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromBrand(b))
+}
+
+func (h *BrandHandler) Update(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req dto.BrandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid request payload", err.Error())
+		return
+	}
+	b, err := h.svc.Update(c, id, req.Name, req.Aliases)
+	if err != nil {
+		brandError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromBrand(b))
+}
+
+func (h *BrandHandler) Delete(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(c, id); err != nil {
+		brandError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func brandError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apperror.JSONError(c, http.StatusNotFound, "not_found", "brand not found", nil)
+	case errors.Is(err, models.ErrBrandConflict):
+		apperror.JSONError(c, http.StatusConflict, "brand_conflict", err.Error(), nil)
+	case errors.Is(err, models.ErrBrandInUse):
+		apperror.JSONError(c, http.StatusConflict, "brand_in_use", err.Error(), nil)
+	case errors.Is(err, models.ErrInvalidBrand):
+		apperror.JSONError(c, http.StatusUnprocessableEntity, "invalid_brand", err.Error(), nil)
+	default:
+		httpError(c, err)
+	}
+}
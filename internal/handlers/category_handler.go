@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go-backend/internal/dto"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	apperror "go-backend/pkg/error"
+	"gorm.io/gorm"
+)
+
+type CategoryHandler struct{ svc *services.CategoryService }
+
+func NewCategoryHandler(s *services.CategoryService) *CategoryHandler {
+	return &CategoryHandler{svc: s}
+}
+
+func (h *CategoryHandler) List(c *gin.Context) {
+	list, err := h.svc.List(c)
+	if err != nil {
+		categoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromCategorySchemas(list))
+}
+
+func (h *CategoryHandler) Get(c *gin.Context) {
+	s, err := h.svc.Get(c, c.Param("category"))
+	if err != nil {
+		categoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromCategorySchema(s))
+}
+
+// Put takes the JSON Schema document itself as the request body.
+func (h *CategoryHandler) Put(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid request payload", err.Error())
+		return
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(body, &obj); err != nil || obj == nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid request payload", "the body must be a JSON Schema object")
+		return
+	}
+	s, err := h.svc.Put(c, c.Param("category"), string(body))
+	if err != nil {
+		categoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromCategorySchema(s))
+}
+
+func (h *CategoryHandler) Delete(c *gin.Context) {
+	if err := h.svc.Delete(c, c.Param("category")); err != nil {
+		categoryError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func categoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apperror.JSONError(c, http.StatusNotFound, "not_found", "category schema not found", nil)
+	case errors.Is(err, models.ErrInvalidSchema):
+		apperror.JSONError(c, http.StatusUnprocessableEntity, "invalid_schema", err.Error(), nil)
+	default:
+		httpError(c, err)
+	}
+}
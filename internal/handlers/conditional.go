@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CachePolicy holds the Cache-Control values sent with device reads; empty
+// values send none.
+type CachePolicy struct {
+	Get  string
+	List string
+}
+
+// writeCacheable renders v as JSON with an ETag over the body and, when
+// modified is known, Last-Modified, answering 304 if the client's copy is
+// still current.
+func writeCacheable(c *gin.Context, v any, modified time.Time, cacheControl string) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		httpError(c, err)
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	h := c.Writer.Header()
+	h.Set("ETag", etag)
+	h.Add("Vary", TimeFormatHeader)
+	if cacheControl != "" {
+		h.Set("Cache-Control", cacheControl)
+	}
+	if !modified.IsZero() {
+		h.Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+	if notModified(c.Request, etag, modified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when it is
+// absent, as RFC 9110 prescribes for GET.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if modified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !modified.Truncate(time.Second).After(since)
+}
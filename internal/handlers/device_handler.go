@@ -1,33 +1,61 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"go-backend/internal/dto"
 	"go-backend/internal/models"
+	"go-backend/internal/openapi"
 	"go-backend/internal/services"
 	apperror "go-backend/pkg/error"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 )
 
-type DeviceHandler struct{ svc *services.DeviceService }
+// TimeFormatHeader selects the time format of a response when the
+// time_format query parameter is absent.
+const TimeFormatHeader = "X-Time-Format"
 
-func NewDeviceHandler(s *services.DeviceService) *DeviceHandler { return &DeviceHandler{svc: s} }
+type DeviceHandler struct {
+	svc        *services.DeviceService
+	mapper     dto.DeviceMapper
+	timeFormat models.TimeFormat
+	cache      CachePolicy
+}
+
+// NewDeviceHandler serves devices in the response shape of mapper, with
+// timestamps in tf unless the request asks for another format.
+func NewDeviceHandler(s *services.DeviceService, m dto.DeviceMapper, tf models.TimeFormat, cache CachePolicy) *DeviceHandler {
+	return &DeviceHandler{svc: s, mapper: m, timeFormat: tf, cache: cache}
+}
 
 func (h *DeviceHandler) Create(c *gin.Context) {
+	tf, ok := responseTimeFormat(c, h.timeFormat)
+	if !ok {
+		return
+	}
 	var req dto.CreateDeviceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid request payload", err.Error())
 		return
 	}
-	d := models.Device{Name: req.Name, Brand: req.Brand, State: models.State(req.State), CreatedAt: models.NowFormattedTime()}
+	d := models.Device{
+		Name: req.Name, Brand: req.Brand, State: models.State(req.State),
+		SerialNumber: req.SerialNumber, AssetTag: req.AssetTag,
+		Category: req.Category, Attributes: req.Attributes, CreatedAt: models.NowFormattedTime(),
+	}
 	id, err := h.svc.Create(c, &d)
 	if err != nil {
 		httpError(c, err)
 		return
 	}
 	d.ID = id
-	c.JSON(http.StatusCreated, dto.FromModel(&d))
+	c.JSON(http.StatusCreated, h.mapper.Device(&d, tf))
 }
 
 func (h *DeviceHandler) Get(c *gin.Context) {
@@ -35,23 +63,118 @@ func (h *DeviceHandler) Get(c *gin.Context) {
 	if !ok {
 		return
 	}
-	d, err := h.svc.Get(c, id)
+	tf, ok := responseTimeFormat(c, h.timeFormat)
+	if !ok {
+		return
+	}
+	fields, columns, ok := h.fields(c)
+	if !ok {
+		return
+	}
+	if len(columns) > 0 && !slices.Contains(columns, "updated_at") {
+		columns = append(columns, "updated_at")
+	}
+	d, err := h.svc.Get(c, id, columns...)
 	if err != nil {
 		httpError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, dto.FromModel(d))
+	writeCacheable(c, dto.Sparse(h.mapper.Device(d, tf), fields), d.UpdatedAt.Time, h.cache.Get)
+}
+
+// BySerial looks a device up by its serial number.
+func (h *DeviceHandler) BySerial(c *gin.Context) {
+	h.lookup(c, h.svc.GetBySerial, c.Param("serial"))
+}
+
+// ByAssetTag looks a device up by its asset tag.
+func (h *DeviceHandler) ByAssetTag(c *gin.Context) {
+	h.lookup(c, h.svc.GetByAssetTag, c.Param("asset_tag"))
+}
+
+func (h *DeviceHandler) lookup(c *gin.Context, get func(context.Context, string) (*models.Device, error), value string) {
+	tf, ok := responseTimeFormat(c, h.timeFormat)
+	if !ok {
+		return
+	}
+	d, err := get(c, value)
+	if err != nil {
+		httpError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, h.mapper.Device(d, tf))
 }
 
 func (h *DeviceHandler) List(c *gin.Context) {
-	brand := c.Query("brand")
-	state := c.Query("state")
-	list, err := h.svc.List(c, brand, state)
+	tf, ok := responseTimeFormat(c, h.timeFormat)
+	if !ok {
+		return
+	}
+	filter, ok := listFilter(c)
+	if !ok {
+		return
+	}
+	fields, columns, ok := h.fields(c)
+	if !ok {
+		return
+	}
+	// Read before the list so a concurrent change can only make
+	// Last-Modified too old, which costs a refetch rather than a stale copy.
+	modified, err := h.svc.LastChange(c)
+	if err != nil {
+		httpError(c, err)
+		return
+	}
+	list, err := h.svc.List(c, filter, columns...)
+	if err != nil {
+		httpError(c, err)
+		return
+	}
+	writeCacheable(c, dto.Sparse(h.mapper.Devices(list, tf), fields), modified, h.cache.List)
+}
+
+// Stats counts devices grouped by any of brand and state (group_by) and by
+// creation period (interval).
+func (h *DeviceHandler) Stats(c *gin.Context) {
+	var req dto.DeviceStatsQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid query parameters", err.Error())
+		return
+	}
+	q := models.StatsQuery{Interval: models.StatsInterval(req.Interval), Brand: req.Brand, State: req.State}
+	if req.GroupBy != "" {
+		for _, g := range strings.Split(req.GroupBy, ",") {
+			switch strings.TrimSpace(g) {
+			case "brand":
+				q.ByBrand = true
+			case "state":
+				q.ByState = true
+			default:
+				apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid group_by", "group_by must list brand and/or state")
+				return
+			}
+		}
+	}
+	for _, d := range []struct {
+		raw string
+		dst *time.Time
+	}{{req.From, &q.From}, {req.To, &q.To}} {
+		if d.raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.DateOnly, d.raw)
+		if err != nil {
+			apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid date", "from and to must be YYYY-MM-DD")
+			return
+		}
+		*d.dst = t
+	}
+	counts, err := h.svc.Stats(c, q)
 	if err != nil {
 		httpError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, dto.FromModels(list))
+	c.JSON(http.StatusOK, dto.FromDeviceCounts(q, counts))
 }
 
 func (h *DeviceHandler) Update(c *gin.Context) {
@@ -66,7 +189,7 @@ func (h *DeviceHandler) Update(c *gin.Context) {
 	}
 	var created models.FormattedTime
 	if req.CreatedAt != nil {
-		created = models.NewFormattedTime(*req.CreatedAt)
+		created = models.NewFormattedTime(req.CreatedAt.Time)
 	} else {
 		ex, err := h.svc.Get(c, id)
 		if err != nil {
@@ -75,7 +198,11 @@ func (h *DeviceHandler) Update(c *gin.Context) {
 		}
 		created = ex.CreatedAt
 	}
-	d := models.Device{Name: req.Name, Brand: req.Brand, State: models.State(req.State), CreatedAt: created}
+	d := models.Device{
+		Name: req.Name, Brand: req.Brand, State: models.State(req.State),
+		SerialNumber: req.SerialNumber, AssetTag: req.AssetTag,
+		Category: req.Category, Attributes: req.Attributes, CreatedAt: created,
+	}
 	if err := h.svc.Update(c, id, &d); err != nil {
 		httpError(c, err)
 		return
@@ -83,11 +210,22 @@ func (h *DeviceHandler) Update(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// Patch accepts a partial JSON object, a JSON Merge Patch (RFC 7396) or a
+// JSON Patch (RFC 6902) depending on the Content-Type.
 func (h *DeviceHandler) Patch(c *gin.Context) {
 	id, ok := parseID(c)
 	if !ok {
 		return
 	}
+	switch c.ContentType() {
+	case openapi.MergePatchContentType, openapi.JSONPatchContentType:
+		h.patchDocument(c, id)
+		return
+	case "", binding.MIMEJSON:
+	default:
+		apperror.JSONError(c, http.StatusUnsupportedMediaType, "unsupported_media_type", "unsupported content type", nil)
+		return
+	}
 	var req dto.PatchDeviceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid request payload", err.Error())
@@ -103,6 +241,18 @@ func (h *DeviceHandler) Patch(c *gin.Context) {
 	if req.State != nil {
 		m["state"] = *req.State
 	}
+	if req.SerialNumber != nil {
+		m["serial_number"] = *req.SerialNumber
+	}
+	if req.AssetTag != nil {
+		m["asset_tag"] = *req.AssetTag
+	}
+	if req.Category != nil {
+		m["category"] = *req.Category
+	}
+	if req.Attributes != nil {
+		m["attributes"] = models.Attributes(req.Attributes)
+	}
 	if err := h.svc.Patch(c, id, m); err != nil {
 		httpError(c, err)
 		return
@@ -122,27 +272,123 @@ func (h *DeviceHandler) Delete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// listFilter reads brand, state, category, attr.<name> attribute values and
+// the comma separated tag list; devices match any of the tags unless
+// tag_match=all.
+func listFilter(c *gin.Context) (models.DeviceFilter, bool) {
+	f := models.DeviceFilter{Brand: c.Query("brand"), State: c.Query("state"), Category: c.Query("category")}
+	for key, values := range c.Request.URL.Query() {
+		name, ok := strings.CutPrefix(key, "attr.")
+		if !ok {
+			continue
+		}
+		if _, err := models.AttributePath(name); err != nil {
+			apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid attribute filter", err.Error())
+			return f, false
+		}
+		if f.Attributes == nil {
+			f.Attributes = map[string]string{}
+		}
+		f.Attributes[name] = values[0]
+	}
+	switch c.Query("tag_match") {
+	case "", "any":
+	case "all":
+		f.AllTags = true
+	default:
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid tag_match", "tag_match must be any or all")
+		return f, false
+	}
+	if raw := c.Query("tag"); raw != "" {
+		tags, err := models.NormalizeTags(strings.Split(raw, ","))
+		if err != nil {
+			apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid tag", err.Error())
+			return f, false
+		}
+		f.Tags = tags
+	}
+	return f, true
+}
+
 func parseID(c *gin.Context) (int64, bool) {
 	sid := c.Param("id")
 	id, err := strconv.ParseInt(sid, 10, 64)
 	if err != nil {
-		c.String(http.StatusBadRequest, "invalid id")
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid id", nil)
 		return 0, false
 	}
 	return id, true
 }
 
+// fields parses the fields parameter into the response fields to render and
+// the columns to read for them.
+func (h *DeviceHandler) fields(c *gin.Context) ([]string, []string, bool) {
+	fields, columns, err := dto.SelectFields(c.Query("fields"), h.mapper.Columns())
+	if err != nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid fields", err.Error())
+		return nil, nil, false
+	}
+	return fields, columns, true
+}
+
+// responseTimeFormat picks the time format from the time_format query
+// parameter, then the X-Time-Format header, falling back to def.
+func responseTimeFormat(c *gin.Context, def models.TimeFormat) (models.TimeFormat, bool) {
+	raw := c.Query("time_format")
+	if raw == "" {
+		raw = c.GetHeader(TimeFormatHeader)
+	}
+	if raw == "" {
+		return def, true
+	}
+	if tf := models.TimeFormat(raw); tf.Valid() {
+		return tf, true
+	}
+	apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid time format", "time_format must be one of rfc3339, epoch, legacy")
+	return "", false
+}
+
 func httpError(c *gin.Context, err error) {
-	switch err {
-	case models.ErrCannotDeleteInUse:
-		apperror.JSONError(c, http.StatusConflict, "in_use_delete_blocked", err.Error(), nil)
-	case models.ErrCannotUpdateCreated:
-		apperror.JSONError(c, http.StatusUnprocessableEntity, "cannot_update_created_at", err.Error(), nil)
-	case models.ErrCannotUpdateFields:
-		apperror.JSONError(c, http.StatusUnprocessableEntity, "cannot_update_name_brand_in_use", err.Error(), nil)
-	case models.ErrInvalidState:
-		apperror.JSONError(c, http.StatusUnprocessableEntity, "invalid_state", err.Error(), nil)
+	var attrs *models.AttributesError
+	if errors.As(err, &attrs) {
+		apperror.JSONError(c, http.StatusUnprocessableEntity, "invalid_attributes", err.Error(), attrs.Problems)
+		return
+	}
+	var dup *models.DuplicateError
+	if errors.As(err, &dup) {
+		apperror.JSONError(c, http.StatusConflict, "duplicate_device", err.Error(),
+			dto.DuplicateDetails{Field: dup.Field, ExistingID: dup.DeviceID})
+		return
+	}
+	status, code := errorCode(err)
+	apperror.JSONError(c, status, code, err.Error(), nil)
+}
+
+func errorCode(err error) (int, string) {
+	switch {
+	case errors.Is(err, models.ErrCannotDeleteInUse):
+		return http.StatusConflict, "in_use_delete_blocked"
+	case errors.Is(err, models.ErrNotAvailable):
+		return http.StatusConflict, "device_not_available"
+	case errors.Is(err, models.ErrNotInUse):
+		return http.StatusConflict, "device_not_in_use"
+	case errors.Is(err, models.ErrConcurrentModification):
+		return http.StatusConflict, "concurrent_modification"
+	case errors.Is(err, models.ErrCannotUpdateCreated):
+		return http.StatusUnprocessableEntity, "cannot_update_created_at"
+	case errors.Is(err, models.ErrCannotUpdateFields):
+		return http.StatusUnprocessableEntity, "cannot_update_name_brand_in_use"
+	case errors.Is(err, models.ErrInvalidState):
+		return http.StatusUnprocessableEntity, "invalid_state"
+	case errors.Is(err, models.ErrUnknownBrand):
+		return http.StatusUnprocessableEntity, "unknown_brand"
+	case errors.Is(err, models.ErrInvalidTag):
+		return http.StatusBadRequest, "invalid_tag"
+	case errors.Is(err, models.ErrInvalidIdentifier):
+		return http.StatusBadRequest, "invalid_identifier"
+	case errors.Is(err, models.ErrDeviceNotFound):
+		return http.StatusNotFound, "not_found"
 	default:
-		apperror.JSONError(c, http.StatusInternalServerError, "internal_error", err.Error(), nil)
+		return http.StatusInternalServerError, "internal_error"
 	}
 }
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin"
+	"go-backend/internal/dto"
+	"go-backend/internal/models"
+	"go-backend/internal/openapi"
+	apperror "go-backend/pkg/error"
+)
+
+var errInvalidPatch = errors.New("invalid patch")
+
+// patchDocument applies a merge patch or JSON patch to the current device
+// representation of the handler's API version and saves the fields that changed. The update is
+// conditional on the device still matching the snapshot the patch was
+// applied to, so test operations cannot race with other writers.
+func (h *DeviceHandler) patchDocument(c *gin.Context, id int64) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid request payload", err.Error())
+		return
+	}
+	var apply func([]byte) ([]byte, error)
+	if c.ContentType() == openapi.MergePatchContentType {
+		if !json.Valid(body) {
+			apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid merge patch", nil)
+			return
+		}
+		apply = func(doc []byte) ([]byte, error) { return jsonpatch.MergePatch(doc, body) }
+	} else {
+		patch, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid JSON patch", err.Error())
+			return
+		}
+		apply = patch.Apply
+	}
+
+	tf, ok := responseTimeFormat(c, h.timeFormat)
+	if !ok {
+		return
+	}
+	existing, err := h.svc.Get(c, id)
+	if err != nil {
+		httpError(c, err)
+		return
+	}
+	before, err := json.Marshal(h.mapper.Device(existing, tf))
+	if err != nil {
+		httpError(c, err)
+		return
+	}
+	after, err := apply(before)
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		apperror.JSONError(c, http.StatusConflict, "patch_test_failed", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		apperror.JSONError(c, http.StatusUnprocessableEntity, "invalid_patch", err.Error(), nil)
+		return
+	}
+	fields, err := changedFields(before, after)
+	if err != nil {
+		apperror.JSONError(c, http.StatusUnprocessableEntity, "invalid_patch", err.Error(), nil)
+		return
+	}
+	if len(fields) > 0 {
+		if err := h.svc.PatchIfUnchanged(c, existing, fields); err != nil {
+			httpError(c, err)
+			return
+		}
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// sameTime reports whether two created_at values denote the same instant,
+// whatever time format each is written in.
+func sameTime(a, b any) bool {
+	var ta, tb dto.Timestamp
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return ta.UnmarshalJSON(ja) == nil && tb.UnmarshalJSON(jb) == nil && ta.Equal(tb.Time)
+}
+
+// changedFields diffs two device representations into column updates.
+// created_at is passed through so the service rejects it like any other
+// attempt to change it.
+func changedFields(before, after []byte) (map[string]any, error) {
+	var old, cur map[string]any
+	if err := json.Unmarshal(before, &old); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(after, &cur); err != nil {
+		return nil, fmt.Errorf("%w: result is not an object", errInvalidPatch)
+	}
+	for k := range cur {
+		if _, ok := old[k]; !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", errInvalidPatch, k)
+		}
+	}
+	fields := map[string]any{}
+	for k, v := range old {
+		nv, ok := cur[k]
+		if ok && reflect.DeepEqual(v, nv) {
+			continue
+		}
+		switch k {
+		case "id", "updated_at":
+			return nil, fmt.Errorf("%w: %s cannot be changed", errInvalidPatch, k)
+		case "created_at":
+			if !sameTime(v, nv) {
+				fields[k] = nv
+			}
+			continue
+		case "category":
+			// Removing the category leaves the device uncategorized.
+			str, isString := nv.(string)
+			if nv != nil && !isString {
+				return nil, fmt.Errorf("%w: category must be a string", errInvalidPatch)
+			}
+			fields[k] = str
+			continue
+		case "serial_number", "asset_tag":
+			// Removing an identifier clears it.
+			str, isString := nv.(string)
+			if nv != nil && !isString {
+				return nil, fmt.Errorf("%w: %s must be a string", errInvalidPatch, k)
+			}
+			fields[k] = str
+			continue
+		case "attributes":
+			obj, isObject := nv.(map[string]any)
+			if nv != nil && !isObject {
+				return nil, fmt.Errorf("%w: attributes must be an object", errInvalidPatch)
+			}
+			fields[k] = models.Attributes(obj)
+			continue
+		}
+		str, isString := nv.(string)
+		if !ok || !isString || str == "" {
+			return nil, fmt.Errorf("%w: %s must be a non-empty string", errInvalidPatch, k)
+		}
+		fields[k] = str
+	}
+	return fields, nil
+}
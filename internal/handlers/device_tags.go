@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"go-backend/internal/dto"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *DeviceHandler) Tags(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	tags, err := h.svc.Tags(c, id)
+	if err != nil {
+		httpError(c, err)
+		return
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	c.JSON(http.StatusOK, dto.DeviceTagsResponse{Tags: tags})
+}
+
+func (h *DeviceHandler) AddTag(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.AddTag(c, id, c.Param("tag")); err != nil {
+		httpError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *DeviceHandler) RemoveTag(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.RemoveTag(c, id, c.Param("tag")); err != nil {
+		httpError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *DeviceHandler) ListTags(c *gin.Context) {
+	counts, err := h.svc.ListTags(c)
+	if err != nil {
+		httpError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromTagCounts(counts))
+}
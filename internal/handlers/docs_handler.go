@@ -1,21 +1,38 @@
 package handlers
 
 import (
-	"github.com/gin-gonic/gin"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"mime"
 	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go-backend/docs"
+	"go-backend/internal/openapi"
+	apperror "go-backend/pkg/error"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	assetCacheControl = "public, max-age=86400"
+	specCacheControl  = "no-cache"
 )
 
-func Docs(c *gin.Context) {
-	html := `<!doctype html>
+const swaggerHTML = `<!doctype html>
 <html>
   <head>
     <meta charset="utf-8" />
     <title>Devices API Docs</title>
-    <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css" />
+    <link rel="stylesheet" href="/docs/assets/swagger-ui/swagger-ui.css" />
+    <link rel="icon" type="image/png" href="/docs/assets/swagger-ui/favicon-32x32.png" sizes="32x32" />
   </head>
   <body>
     <div id="swagger-ui"></div>
-    <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+    <script src="/docs/assets/swagger-ui/swagger-ui-bundle.js"></script>
     <script>
       window.ui = SwaggerUIBundle({
         url: '/openapi.yaml',
@@ -26,5 +43,110 @@ func Docs(c *gin.Context) {
     </script>
   </body>
 </html>`
-	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
+
+const redocHTML = `<!doctype html>
+<html>
+  <head>
+    <meta charset="utf-8" />
+    <title>Devices API Reference</title>
+  </head>
+  <body>
+    <redoc spec-url="/openapi.yaml"></redoc>
+    <script src="/docs/assets/redoc/redoc.standalone.js"></script>
+  </body>
+</html>`
+
+const redocBundle = "redoc/redoc.standalone.js"
+
+type asset struct {
+	body []byte
+	etag string
+}
+
+type DocsHandler struct {
+	assets   map[string]asset
+	specYAML asset
+	specJSON asset
+}
+
+func NewDocsHandler(spec *openapi.Document) (*DocsHandler, error) {
+	h := &DocsHandler{assets: map[string]asset{}}
+	ui, err := fs.Sub(docs.UI, "ui")
+	if err != nil {
+		return nil, err
+	}
+	err = fs.WalkDir(ui, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(ui, p)
+		if err != nil {
+			return err
+		}
+		h.assets[p] = newAsset(b)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	y, err := yaml.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	js, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	h.specYAML = newAsset(y)
+	h.specJSON = newAsset(js)
+	return h, nil
+}
+
+func newAsset(b []byte) asset {
+	sum := sha256.Sum256(b)
+	return asset{body: b, etag: `"` + hex.EncodeToString(sum[:8]) + `"`}
+}
+
+func (h *DocsHandler) SwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerHTML))
+}
+
+func (h *DocsHandler) ReDoc(c *gin.Context) {
+	if _, ok := h.assets[redocBundle]; !ok {
+		apperror.JSONError(c, http.StatusNotFound, "redoc_unavailable", "redoc bundle is not embedded in this build", nil)
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(redocHTML))
+}
+
+func (h *DocsHandler) Assets(c *gin.Context) {
+	name := strings.TrimPrefix(c.Param("filepath"), "/")
+	a, ok := h.assets[name]
+	if !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	ct := mime.TypeByExtension(path.Ext(name))
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	serveAsset(c, a, ct, assetCacheControl)
+}
+
+func (h *DocsHandler) SpecYAML(c *gin.Context) {
+	serveAsset(c, h.specYAML, "application/yaml", specCacheControl)
+}
+
+func (h *DocsHandler) SpecJSON(c *gin.Context) {
+	serveAsset(c, h.specJSON, "application/json", specCacheControl)
+}
+
+func serveAsset(c *gin.Context, a asset, contentType, cacheControl string) {
+	c.Header("ETag", a.etag)
+	c.Header("Cache-Control", cacheControl)
+	if c.GetHeader("If-None-Match") == a.etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, contentType, a.body)
 }
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go-backend/internal/dto"
+	"go-backend/internal/events"
+	"go-backend/internal/models"
+	apperror "go-backend/pkg/error"
+)
+
+const sseClientBuffer = 64
+
+type EventsHandler struct {
+	broker     *events.Broker
+	heartbeat  time.Duration
+	timeFormat models.TimeFormat
+}
+
+func NewEventsHandler(b *events.Broker, heartbeat time.Duration, tf models.TimeFormat) *EventsHandler {
+	return &EventsHandler{broker: b, heartbeat: heartbeat, timeFormat: tf}
+}
+
+// Stream serves device changes as Server-Sent Events. Clients resume with the
+// Last-Event-ID header (or last_event_id query parameter); when the requested
+// position is no longer buffered, or was issued before a restart or by another
+// instance, a "reset" event tells them to refetch.
+func (h *EventsHandler) Stream(c *gin.Context) {
+	var q dto.DeviceEventsQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid query parameters", err.Error())
+		return
+	}
+	tf, ok := responseTimeFormat(c, h.timeFormat)
+	if !ok {
+		return
+	}
+	lastID := q.LastEventID
+	if v := c.GetHeader("Last-Event-ID"); v != "" {
+		lastID = v
+	}
+	sub, replay, complete := h.broker.Subscribe(lastID, sseClientBuffer, deviceFilter(q.Brand, q.State))
+	defer sub.Close()
+
+	// Streams outlive the server's write timeout.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, "retry: 3000\n\n")
+	if !complete {
+		fmt.Fprint(c.Writer, "event: reset\ndata: {}\n\n")
+	}
+	for _, ev := range replay {
+		writeSSE(c, ev, tf)
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case ev, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			writeSSE(c, ev, tf)
+		case <-ticker.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeSSE(c *gin.Context, ev events.Event, tf models.TimeFormat) {
+	data, _ := json.Marshal(dto.FromEvent(ev, tf))
+	fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+}
+
+func deviceFilter(brand, state string) events.Filter {
+	if brand == "" && state == "" {
+		return nil
+	}
+	return func(ev events.Event) bool {
+		return (brand == "" || ev.Device.Brand == brand) && (state == "" || string(ev.Device.State) == state)
+	}
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/ast"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"go-backend/internal/dto"
+	"go-backend/internal/services"
+	apperror "go-backend/pkg/error"
+)
+
+//go:embed schema.graphql
+var graphQLSchema string
+
+type GraphQLHandler struct {
+	schema        *graphql.Schema
+	fields        gqlFields
+	maxComplexity int
+}
+
+// NewGraphQLHandler serves the device schema. Queries deeper than maxDepth
+// are rejected during validation; operations whose estimated cost exceeds
+// maxComplexity are rejected before any of their fields run.
+func NewGraphQLHandler(s *services.DeviceService, maxDepth, maxComplexity int) *GraphQLHandler {
+	schema := graphql.MustParseSchema(graphQLSchema, &gqlResolver{svc: s}, graphql.MaxDepth(maxDepth))
+	return &GraphQLHandler{schema: schema, fields: newGQLFields(schema.AST()), maxComplexity: maxComplexity}
+}
+
+func (h *GraphQLHandler) Serve(c *gin.Context) {
+	var req dto.GraphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid request payload", err.Error())
+		return
+	}
+	var vars map[string]any
+	if req.Variables != nil {
+		vars = *req.Variables
+	}
+	if errs := h.schema.ValidateWithVariables(req.Query, vars); len(errs) > 0 {
+		for _, e := range errs {
+			code := "validation_error"
+			if e.Rule == "MaxDepthExceeded" {
+				code = "query_too_deep"
+			}
+			e.Extensions = map[string]any{"code": code}
+		}
+		c.JSON(http.StatusOK, &graphql.Response{Errors: errs})
+		return
+	}
+	if cost := h.complexity(c, req.Query, req.OperationName, vars); cost > h.maxComplexity {
+		c.JSON(http.StatusOK, &graphql.Response{Errors: []*gqlerrors.QueryError{{
+			Message:    fmt.Sprintf("query complexity %d exceeds the maximum of %d", cost, h.maxComplexity),
+			Extensions: map[string]any{"code": "query_too_complex"},
+		}}})
+		return
+	}
+	c.JSON(http.StatusOK, h.schema.Exec(c, req.Query, req.OperationName, vars))
+}
+
+// complexity estimates the cost of the whole operation before any of it runs.
+// graph-gophers keeps its query parser internal, so the operation is executed
+// once in pricing mode: every root resolver adds the cost of its selection to
+// the estimate and returns before doing any work.
+func (h *GraphQLHandler) complexity(ctx context.Context, query, operationName string, vars map[string]any) int {
+	e := &complexityEstimate{fields: h.fields}
+	h.schema.Exec(context.WithValue(ctx, complexityKey{}, e), query, operationName, vars)
+	return int(e.total.Load())
+}
+
+// gqlField is what the complexity estimate needs to know about a field of
+// an object type: the type it returns and the default of its `first`
+// argument, if it has one.
+type gqlField struct {
+	typ   string
+	first int
+}
+
+// gqlFields maps "Type.field" to the field.
+type gqlFields map[string]gqlField
+
+func newGQLFields(s *ast.Schema) gqlFields {
+	fields := gqlFields{}
+	for _, o := range s.Objects {
+		for _, f := range o.Fields {
+			t := f.Type
+			for {
+				if n, ok := t.(*ast.NonNull); ok {
+					t = n.OfType
+				} else if l, ok := t.(*ast.List); ok {
+					t = l.OfType
+				} else {
+					break
+				}
+			}
+			field := gqlField{typ: t.(ast.NamedType).TypeName()}
+			if a := f.Arguments.Get("first"); a != nil && a.Default != nil {
+				field.first, _ = intArg(a.Default.Deserialize(nil))
+			}
+			fields[o.Name+"."+f.Name] = field
+		}
+	}
+	return fields
+}
+
+type complexityKey struct{}
+
+// complexityEstimate collects the cost of every root field of an operation
+// during the pricing run.
+type complexityEstimate struct {
+	fields gqlFields
+	total  atomic.Int64
+}
+
+// errPriced ends a resolver during the pricing run.
+var errPriced = errors.New("priced")
+
+// priceComplexity adds the estimated cost of the root field being resolved
+// to the pricing run in ctx and returns errPriced, so that the resolver
+// returns before it touches the database; outside the pricing run it returns
+// nil. Every field costs one, and the selection under a field with a `first`
+// argument is counted once per requested item, defaults included. typ is the
+// type the root field returns and first its own `first` argument, or zero.
+func priceComplexity(ctx context.Context, typ string, first int) error {
+	e, ok := ctx.Value(complexityKey{}).(*complexityEstimate)
+	if !ok {
+		return nil
+	}
+	paths := graphql.SelectedFieldNames(ctx)
+	type selected struct {
+		typ        string
+		multiplier int
+	}
+	seen := make(map[string]selected, len(paths))
+	children := 0
+	for _, p := range paths {
+		parent, name := selected{typ: typ, multiplier: 1}, p
+		if i := strings.LastIndexByte(p, '.'); i >= 0 {
+			parent, name = seen[p[:i]], p[i+1:]
+		}
+		children += parent.multiplier
+		f := e.fields[parent.typ+"."+name]
+		n := f.first
+		var args struct{ First int32 }
+		if ok, _ := graphql.DecodeSelectedFieldArgs(ctx, p, &args); ok {
+			n = int(args.First)
+		}
+		seen[p] = selected{typ: f.typ, multiplier: parent.multiplier * max(n, 1)}
+	}
+	e.total.Add(int64(1 + children*max(first, 1)))
+	return errPriced
+}
+
+func intArg(v any) (int, bool) {
+	switch n := v.(type) {
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case int:
+		return n, true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	"go-backend/pkg/utils"
+)
+
+const gqlMaxPage = 100
+
+// gqlError carries the REST error code in the GraphQL error extensions.
+type gqlError struct {
+	code string
+	msg  string
+}
+
+func (e *gqlError) Error() string { return e.msg }
+
+func (e *gqlError) Extensions() map[string]any { return map[string]any{"code": e.code} }
+
+func gqlErr(err error) error {
+	_, code := errorCode(err)
+	return &gqlError{code: code, msg: err.Error()}
+}
+
+func gqlValidation(msg string) error { return &gqlError{code: "validation_error", msg: msg} }
+
+type gqlResolver struct{ svc *services.DeviceService }
+
+func parseGQLID(id graphql.ID) (int64, error) {
+	n, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil {
+		return 0, gqlValidation("invalid id")
+	}
+	return n, nil
+}
+
+func gqlState(s string) models.State {
+	return models.State(strings.ReplaceAll(strings.ToLower(s), "_", "-"))
+}
+
+func gqlStateName(s models.State) string {
+	return strings.ReplaceAll(strings.ToUpper(string(s)), "-", "_")
+}
+
+func (r *gqlResolver) Device(ctx context.Context, args struct{ ID graphql.ID }) (*gqlDevice, error) {
+	if err := priceComplexity(ctx, "Device", 0); err != nil {
+		return nil, err
+	}
+	id, err := parseGQLID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	d, err := r.svc.Get(ctx, id)
+	if errors.Is(err, models.ErrDeviceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, gqlErr(err)
+	}
+	return &gqlDevice{svc: r.svc, d: *d}, nil
+}
+
+type gqlDeviceFilter struct {
+	Brand *string
+	State *string
+}
+
+func (r *gqlResolver) Devices(ctx context.Context, args struct {
+	Filter *gqlDeviceFilter
+	First  int32
+	After  *string
+}) (*gqlDeviceConnection, error) {
+	if args.First < 0 || args.First > gqlMaxPage {
+		return nil, gqlValidation("first must be between 0 and " + strconv.Itoa(gqlMaxPage))
+	}
+	if err := priceComplexity(ctx, "DeviceConnection", int(args.First)); err != nil {
+		return nil, err
+	}
+	var after int64
+	if args.After != nil {
+		var err error
+		if after, err = utils.DecodeCursor(*args.After); err != nil {
+			return nil, gqlValidation("invalid cursor")
+		}
+	}
+	var brand, state string
+	if f := args.Filter; f != nil {
+		if f.Brand != nil {
+			brand = *f.Brand
+		}
+		if f.State != nil {
+			state = string(gqlState(*f.State))
+		}
+	}
+	size := int(args.First)
+	list, err := r.svc.ListPage(ctx, models.DeviceFilter{Brand: brand, State: state}, after, size+1)
+	if err != nil {
+		return nil, gqlErr(err)
+	}
+	conn := &gqlDeviceConnection{}
+	if len(list) > size {
+		list = list[:size]
+		conn.hasNext = true
+	}
+	ids := make([]int64, len(list))
+	for i, d := range list {
+		ids[i] = d.ID
+	}
+	history := newGQLHistory(r.svc, ids)
+	for _, d := range list {
+		conn.nodes = append(conn.nodes, &gqlDevice{svc: r.svc, d: d, history: history})
+	}
+	if len(list) > 0 {
+		c := utils.EncodeCursor(list[len(list)-1].ID)
+		conn.endCursor = &c
+	}
+	return conn, nil
+}
+
+type gqlDeviceInput struct {
+	Name  string
+	Brand string
+	State string
+}
+
+func (r *gqlResolver) CreateDevice(ctx context.Context, args struct{ Input gqlDeviceInput }) (*gqlDevice, error) {
+	if err := priceComplexity(ctx, "Device", 0); err != nil {
+		return nil, err
+	}
+	if args.Input.Name == "" || args.Input.Brand == "" {
+		return nil, gqlValidation("name and brand are required")
+	}
+	d := models.Device{Name: args.Input.Name, Brand: args.Input.Brand, State: gqlState(args.Input.State), CreatedAt: models.NowFormattedTime()}
+	if _, err := r.svc.Create(ctx, &d); err != nil {
+		return nil, gqlErr(err)
+	}
+	return &gqlDevice{svc: r.svc, d: d}, nil
+}
+
+func (r *gqlResolver) UpdateDevice(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input gqlDeviceInput
+}) (*gqlDevice, error) {
+	if err := priceComplexity(ctx, "Device", 0); err != nil {
+		return nil, err
+	}
+	id, err := parseGQLID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	if args.Input.Name == "" || args.Input.Brand == "" {
+		return nil, gqlValidation("name and brand are required")
+	}
+	existing, err := r.svc.Get(ctx, id)
+	if err != nil {
+		return nil, gqlErr(err)
+	}
+	d := models.Device{
+		Name: args.Input.Name, Brand: args.Input.Brand, State: gqlState(args.Input.State),
+		SerialNumber: existing.SerialNumber, AssetTag: existing.AssetTag,
+		Category: existing.Category, Attributes: existing.Attributes, CreatedAt: existing.CreatedAt,
+	}
+	if err := r.svc.Update(ctx, id, &d); err != nil {
+		return nil, gqlErr(err)
+	}
+	return r.reload(ctx, id)
+}
+
+func (r *gqlResolver) PatchDevice(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input struct {
+		Name  *string
+		Brand *string
+		State *string
+	}
+}) (*gqlDevice, error) {
+	if err := priceComplexity(ctx, "Device", 0); err != nil {
+		return nil, err
+	}
+	id, err := parseGQLID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]any{}
+	if args.Input.Name != nil {
+		m["name"] = *args.Input.Name
+	}
+	if args.Input.Brand != nil {
+		m["brand"] = *args.Input.Brand
+	}
+	if args.Input.State != nil {
+		m["state"] = string(gqlState(*args.Input.State))
+	}
+	if err := r.svc.Patch(ctx, id, m); err != nil {
+		return nil, gqlErr(err)
+	}
+	return r.reload(ctx, id)
+}
+
+func (r *gqlResolver) DeleteDevice(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	if err := priceComplexity(ctx, "Boolean", 0); err != nil {
+		return false, err
+	}
+	id, err := parseGQLID(args.ID)
+	if err != nil {
+		return false, err
+	}
+	if err := r.svc.Delete(ctx, id); err != nil {
+		return false, gqlErr(err)
+	}
+	return true, nil
+}
+
+func (r *gqlResolver) CheckOut(ctx context.Context, args struct{ ID graphql.ID }) (*gqlDevice, error) {
+	return r.transition(ctx, args.ID, r.svc.CheckOut)
+}
+
+func (r *gqlResolver) CheckIn(ctx context.Context, args struct{ ID graphql.ID }) (*gqlDevice, error) {
+	return r.transition(ctx, args.ID, r.svc.CheckIn)
+}
+
+func (r *gqlResolver) transition(ctx context.Context, gid graphql.ID, fn func(context.Context, int64) (*models.Device, error)) (*gqlDevice, error) {
+	if err := priceComplexity(ctx, "Device", 0); err != nil {
+		return nil, err
+	}
+	id, err := parseGQLID(gid)
+	if err != nil {
+		return nil, err
+	}
+	d, err := fn(ctx, id)
+	if err != nil {
+		return nil, gqlErr(err)
+	}
+	return &gqlDevice{svc: r.svc, d: *d}, nil
+}
+
+func (r *gqlResolver) reload(ctx context.Context, id int64) (*gqlDevice, error) {
+	d, err := r.svc.Get(ctx, id)
+	if err != nil {
+		return nil, gqlErr(err)
+	}
+	return &gqlDevice{svc: r.svc, d: *d}, nil
+}
+
+type gqlDevice struct {
+	svc     *services.DeviceService
+	d       models.Device
+	history *gqlHistory
+}
+
+func (d *gqlDevice) ID() graphql.ID          { return graphql.ID(strconv.FormatInt(d.d.ID, 10)) }
+func (d *gqlDevice) Name() string            { return d.d.Name }
+func (d *gqlDevice) Brand() string           { return d.d.Brand }
+func (d *gqlDevice) State() string           { return gqlStateName(d.d.State) }
+func (d *gqlDevice) CreatedAt() graphql.Time { return graphql.Time{Time: d.d.CreatedAt.Time} }
+
+func (d *gqlDevice) History(ctx context.Context, args struct{ First int32 }) ([]*gqlDeviceChange, error) {
+	if args.First < 0 || args.First > gqlMaxPage {
+		return nil, gqlValidation("first must be between 0 and " + strconv.Itoa(gqlMaxPage))
+	}
+	h := d.history
+	if h == nil {
+		h = newGQLHistory(d.svc, []int64{d.d.ID})
+	}
+	list, err := h.load(ctx, d.d.ID, int(args.First))
+	if err != nil {
+		return nil, gqlErr(err)
+	}
+	out := make([]*gqlDeviceChange, 0, len(list))
+	for _, ch := range list {
+		c := &gqlDeviceChange{ch: ch}
+		if err := json.Unmarshal([]byte(ch.Payload), &c.d); err != nil {
+			return nil, gqlErr(err)
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// gqlHistory loads the history of a page of devices with one query per
+// distinct `first`, the first time any device of the page asks for it.
+type gqlHistory struct {
+	svc *services.DeviceService
+	ids []int64
+
+	mu      sync.Mutex
+	batches map[int]*gqlHistoryBatch
+}
+
+type gqlHistoryBatch struct {
+	once    sync.Once
+	changes map[int64][]models.DeviceChange
+	err     error
+}
+
+func newGQLHistory(svc *services.DeviceService, ids []int64) *gqlHistory {
+	return &gqlHistory{svc: svc, ids: ids, batches: map[int]*gqlHistoryBatch{}}
+}
+
+func (h *gqlHistory) load(ctx context.Context, id int64, limit int) ([]models.DeviceChange, error) {
+	h.mu.Lock()
+	b, ok := h.batches[limit]
+	if !ok {
+		b = &gqlHistoryBatch{}
+		h.batches[limit] = b
+	}
+	h.mu.Unlock()
+	b.once.Do(func() { b.changes, b.err = h.svc.History(ctx, h.ids, limit) })
+	return b.changes[id], b.err
+}
+
+type gqlDeviceChange struct {
+	ch models.DeviceChange
+	d  models.Device
+}
+
+func (c *gqlDeviceChange) ID() graphql.ID           { return graphql.ID(strconv.FormatInt(c.ch.ID, 10)) }
+func (c *gqlDeviceChange) Type() string             { return c.ch.Type }
+func (c *gqlDeviceChange) Name() string             { return c.d.Name }
+func (c *gqlDeviceChange) Brand() string            { return c.d.Brand }
+func (c *gqlDeviceChange) State() string            { return gqlStateName(c.d.State) }
+func (c *gqlDeviceChange) OccurredAt() graphql.Time { return graphql.Time{Time: c.ch.CreatedAt} }
+
+type gqlDeviceConnection struct {
+	nodes     []*gqlDevice
+	endCursor *string
+	hasNext   bool
+}
+
+func (c *gqlDeviceConnection) Nodes() []*gqlDevice { return c.nodes }
+
+func (c *gqlDeviceConnection) PageInfo() *gqlPageInfo {
+	return &gqlPageInfo{endCursor: c.endCursor, hasNext: c.hasNext}
+}
+
+type gqlPageInfo struct {
+	endCursor *string
+	hasNext   bool
+}
+
+func (p *gqlPageInfo) EndCursor() *string { return p.endCursor }
+func (p *gqlPageInfo) HasNextPage() bool  { return p.hasNext }
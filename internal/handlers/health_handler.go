@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-backend/internal/health"
+	"net/http"
+)
+
+type HealthHandler struct{ status *health.Status }
+
+func NewHealthHandler(s *health.Status) *HealthHandler { return &HealthHandler{status: s} }
+
+func (h *HealthHandler) Healthz(c *gin.Context) {
+	if !h.status.Ready() {
+		c.Status(http.StatusServiceUnavailable)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+func (h *HealthHandler) Ready(c *gin.Context) {
+	rep := h.status.Check(c.Request.Context())
+	code := http.StatusOK
+	if rep.Status != health.StatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, rep)
+}
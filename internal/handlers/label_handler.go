@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go-backend/internal/dto"
+	"go-backend/internal/labels"
+	"go-backend/internal/services"
+	apperror "go-backend/pkg/error"
+)
+
+const (
+	PNGContentType = "image/png"
+	SVGContentType = "image/svg+xml"
+	PDFContentType = "application/pdf"
+)
+
+type LabelHandler struct {
+	svc      *services.DeviceService
+	renderer *labels.Renderer
+	maxBatch int
+}
+
+// NewLabelHandler prints labels with r; a sheet holds at most maxBatch labels.
+func NewLabelHandler(s *services.DeviceService, r *labels.Renderer, maxBatch int) *LabelHandler {
+	return &LabelHandler{svc: s, renderer: r, maxBatch: maxBatch}
+}
+
+// Device renders the label of one device as a PNG, or an SVG with format=svg.
+func (h *LabelHandler) Device(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	q := dto.DeviceLabelParams{DeviceIDParams: dto.DeviceIDParams{ID: id}}
+	if err := c.ShouldBindQuery(&q); err != nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid query parameters", err.Error())
+		return
+	}
+	format := cmp.Or(q.Format, "png")
+	d, err := h.svc.Get(c, id)
+	if err != nil {
+		httpError(c, err)
+		return
+	}
+	var buf bytes.Buffer
+	contentType := PNGContentType
+	if format == "svg" {
+		contentType, err = SVGContentType, h.renderer.SVG(&buf, d)
+	} else {
+		err = h.renderer.PNG(&buf, d)
+	}
+	if err != nil {
+		httpError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="device-%d.%s"`, id, format))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// Sheet renders the labels of the devices matching the list filters as a PDF.
+func (h *LabelHandler) Sheet(c *gin.Context) {
+	filter, ok := listFilter(c)
+	if !ok {
+		return
+	}
+	// One row past the limit tells an oversized sheet apart without reading
+	// every match.
+	list, err := h.svc.ListPage(c, filter, 0, h.maxBatch+1)
+	if err != nil {
+		httpError(c, err)
+		return
+	}
+	if len(list) > h.maxBatch {
+		apperror.JSONError(c, http.StatusUnprocessableEntity, "too_many_labels",
+			fmt.Sprintf("more than %d devices match, at most %d labels are printed at once", h.maxBatch, h.maxBatch), nil)
+		return
+	}
+	var buf bytes.Buffer
+	if err := h.renderer.PDF(&buf, list); err != nil {
+		httpError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", `inline; filename="device-labels.pdf"`)
+	c.Data(http.StatusOK, PDFContentType, buf.Bytes())
+}
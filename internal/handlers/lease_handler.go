@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go-backend/internal/dto"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	apperror "go-backend/pkg/error"
+	"gorm.io/gorm"
+)
+
+type LeaseHandler struct{ svc *services.LeaseService }
+
+func NewLeaseHandler(s *services.LeaseService) *LeaseHandler { return &LeaseHandler{svc: s} }
+
+// Acquire checks an available device out under a new lease.
+func (h *LeaseHandler) Acquire(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req dto.AcquireLeaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid request payload", err.Error())
+		return
+	}
+	l, err := h.svc.Acquire(c, id, req.Holder, time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		leaseError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.FromLease(l))
+}
+
+// Active returns the lease a device is checked out under.
+func (h *LeaseHandler) Active(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	l, err := h.svc.Active(c, id)
+	if err != nil {
+		leaseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromLease(l))
+}
+
+func (h *LeaseHandler) Get(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	l, err := h.svc.Get(c, id)
+	if err != nil {
+		leaseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromLease(l))
+}
+
+func (h *LeaseHandler) Renew(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req dto.RenewLeaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid request payload", err.Error())
+		return
+	}
+	l, err := h.svc.Renew(c, id, time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		leaseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromLease(l))
+}
+
+// Release ends a lease and returns its device to available.
+func (h *LeaseHandler) Release(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	l, err := h.svc.Release(c, id)
+	if err != nil {
+		leaseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromLease(l))
+}
+
+func leaseError(c *gin.Context, err error) {
+	var conflict *models.ReservationConflictError
+	switch {
+	case errors.As(err, &conflict):
+		apperror.JSONError(c, http.StatusConflict, "reservation_conflict", err.Error(),
+			dto.ReservationConflictDetails{ReservationID: conflict.ReservationID})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apperror.JSONError(c, http.StatusNotFound, "not_found", "lease not found", nil)
+	case errors.Is(err, models.ErrInvalidLease):
+		apperror.JSONError(c, http.StatusUnprocessableEntity, "invalid_lease", err.Error(), nil)
+	case errors.Is(err, models.ErrLeaseNotActive):
+		apperror.JSONError(c, http.StatusConflict, "lease_not_active", err.Error(), nil)
+	default:
+		httpError(c, err)
+	}
+}
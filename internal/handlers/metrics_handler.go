@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"go-backend/internal/dto"
+	"go-backend/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MetricsHandler struct{ devices *services.DeviceService }
+
+func NewMetricsHandler(s *services.DeviceService) *MetricsHandler { return &MetricsHandler{devices: s} }
+
+func (h *MetricsHandler) DeviceCache(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.FromCacheStats(h.devices.CacheStats()))
+}
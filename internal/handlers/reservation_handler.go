@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go-backend/internal/dto"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	apperror "go-backend/pkg/error"
+	"gorm.io/gorm"
+)
+
+type ReservationHandler struct {
+	svc        *services.ReservationService
+	mapper     dto.DeviceMapper
+	timeFormat models.TimeFormat
+}
+
+// NewReservationHandler renders available devices in the response shape of
+// mapper.
+func NewReservationHandler(s *services.ReservationService, m dto.DeviceMapper, tf models.TimeFormat) *ReservationHandler {
+	return &ReservationHandler{svc: s, mapper: m, timeFormat: tf}
+}
+
+func (h *ReservationHandler) Create(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req dto.CreateReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid request payload", err.Error())
+		return
+	}
+	res, err := h.svc.Create(c, id, req.Holder, req.StartsAt, req.EndsAt)
+	if err != nil {
+		reservationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.FromReservation(res))
+}
+
+func (h *ReservationHandler) ListForDevice(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	list, err := h.svc.ListForDevice(c, id)
+	if err != nil {
+		reservationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromReservations(list))
+}
+
+func (h *ReservationHandler) Get(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	res, err := h.svc.Get(c, id)
+	if err != nil {
+		reservationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromReservation(res))
+}
+
+// Cancel withdraws a scheduled reservation or ends an active one.
+func (h *ReservationHandler) Cancel(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	res, err := h.svc.Cancel(c, id)
+	if err != nil {
+		reservationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromReservation(res))
+}
+
+// Available lists the devices that can be booked for the whole window.
+func (h *ReservationHandler) Available(c *gin.Context) {
+	tf, ok := responseTimeFormat(c, h.timeFormat)
+	if !ok {
+		return
+	}
+	var q dto.AvailableDevicesQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid query parameters", err.Error())
+		return
+	}
+	list, err := h.svc.Available(c, models.DeviceFilter{Brand: q.Brand}, q.From, q.To)
+	if err != nil {
+		reservationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, h.mapper.Devices(list, tf))
+}
+
+func reservationError(c *gin.Context, err error) {
+	var conflict *models.ReservationConflictError
+	switch {
+	case errors.As(err, &conflict):
+		apperror.JSONError(c, http.StatusConflict, "reservation_conflict", err.Error(),
+			dto.ReservationConflictDetails{ReservationID: conflict.ReservationID})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apperror.JSONError(c, http.StatusNotFound, "not_found", "reservation not found", nil)
+	case errors.Is(err, models.ErrInvalidReservation):
+		apperror.JSONError(c, http.StatusUnprocessableEntity, "invalid_reservation", err.Error(), nil)
+	case errors.Is(err, models.ErrReservationEnded):
+		apperror.JSONError(c, http.StatusConflict, "reservation_ended", err.Error(), nil)
+	case errors.Is(err, models.ErrInvalidWindow):
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid window", err.Error())
+	default:
+		httpError(c, err)
+	}
+}
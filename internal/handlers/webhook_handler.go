@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go-backend/internal/dto"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+	apperror "go-backend/pkg/error"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler struct{ svc *services.WebhookService }
+
+func NewWebhookHandler(s *services.WebhookService) *WebhookHandler { return &WebhookHandler{svc: s} }
+
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid request payload", err.Error())
+		return
+	}
+	sub, err := h.svc.Create(c, req.URL, req.EventTypes, req.Secret, req.Active == nil || *req.Active)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	resp := dto.FromWebhook(sub)
+	resp.Secret = sub.Secret
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *WebhookHandler) List(c *gin.Context) {
+	list, err := h.svc.List(c)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromWebhooks(list))
+}
+
+func (h *WebhookHandler) Get(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	sub, err := h.svc.Get(c, id)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromWebhook(sub))
+}
+
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid request payload", err.Error())
+		return
+	}
+	sub, err := h.svc.Update(c, id, req.URL, req.EventTypes, req.Active == nil || *req.Active)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromWebhook(sub))
+}
+
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(c, id); err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var q dto.WebhookDeliveriesQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		apperror.JSONError(c, http.StatusBadRequest, "validation_error", "invalid query", err.Error())
+		return
+	}
+	list, err := h.svc.Deliveries(c, id, q.Status)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDeliveries(list))
+}
+
+func (h *WebhookHandler) DeadLetters(c *gin.Context) {
+	list, err := h.svc.DeadLetters(c)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromDeliveries(list))
+}
+
+func (h *WebhookHandler) Retry(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.Retry(c, id); err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+func webhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apperror.JSONError(c, http.StatusNotFound, "not_found", "webhook not found", nil)
+	case errors.Is(err, models.ErrInvalidWebhookURL):
+		apperror.JSONError(c, http.StatusUnprocessableEntity, "invalid_webhook_url", err.Error(), nil)
+	case errors.Is(err, models.ErrWebhookHostDenied):
+		apperror.JSONError(c, http.StatusUnprocessableEntity, "webhook_host_denied", err.Error(), nil)
+	case errors.Is(err, models.ErrInvalidEventType):
+		apperror.JSONError(c, http.StatusUnprocessableEntity, "invalid_event_type", err.Error(), nil)
+	case errors.Is(err, models.ErrDeliveryNotRetrying):
+		apperror.JSONError(c, http.StatusConflict, "delivery_not_dead", err.Error(), nil)
+	default:
+		httpError(c, err)
+	}
+}
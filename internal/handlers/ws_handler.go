@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go-backend/internal/dto"
+	"go-backend/internal/events"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+)
+
+const (
+	wsOutboundBuffer = 64
+	wsMaxMessageSize = 4096
+	wsMaxDevices     = 256
+	wsWriteWait      = 10 * time.Second
+	wsPongWait       = 60 * time.Second
+	wsPingPeriod     = wsPongWait * 9 / 10
+	wsCommandTimeout = 5 * time.Second
+)
+
+type WSHandler struct {
+	svc        *services.DeviceService
+	upgrader   websocket.Upgrader
+	timeFormat models.TimeFormat
+}
+
+// NewWSHandler accepts same-origin connections and cross-origin ones for
+// which originAllowed returns true. Devices are sent with timestamps in tf
+// unless the handshake selects another format.
+func NewWSHandler(s *services.DeviceService, originAllowed func(string) bool, tf models.TimeFormat) *WSHandler {
+	return &WSHandler{svc: s, timeFormat: tf, upgrader: websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return true
+			}
+			if u, err := url.Parse(origin); err == nil && u.Host == r.Host {
+				return true
+			}
+			return originAllowed != nil && originAllowed(origin)
+		},
+	}}
+}
+
+func (h *WSHandler) Serve(c *gin.Context) {
+	tf, ok := responseTimeFormat(c, h.timeFormat)
+	if !ok {
+		return
+	}
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	s := &wsSession{
+		svc:        h.svc,
+		conn:       conn,
+		timeFormat: tf,
+		out:        make(chan dto.WSServerMessage, wsOutboundBuffer),
+		done:       make(chan struct{}),
+		devices:    map[int64]struct{}{},
+	}
+	s.run()
+}
+
+// wsSession is one client connection. A single writer goroutine owns the
+// socket's write side; everything else queues onto out and a client that
+// lets out fill up is disconnected as a slow consumer.
+type wsSession struct {
+	svc        *services.DeviceService
+	conn       *websocket.Conn
+	timeFormat models.TimeFormat
+	out        chan dto.WSServerMessage
+	done       chan struct{}
+
+	closeOnce sync.Once
+	closeCode int
+	closeText string
+
+	mu      sync.RWMutex
+	devices map[int64]struct{}
+}
+
+func (s *wsSession) run() {
+	sub, _, _ := s.svc.Events().Subscribe("", wsOutboundBuffer, s.subscribed)
+	defer sub.Close()
+	go s.writeLoop()
+	go s.pumpEvents(sub)
+	s.readLoop()
+	s.close(websocket.CloseNormalClosure, "")
+}
+
+func (s *wsSession) subscribed(ev events.Event) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.devices[ev.Device.ID]
+	return ok
+}
+
+func (s *wsSession) pumpEvents(sub *events.Subscription) {
+	for {
+		select {
+		case <-s.done:
+			return
+		case ev, ok := <-sub.C:
+			if !ok {
+				s.close(websocket.ClosePolicyViolation, "slow consumer")
+				return
+			}
+			e := dto.FromEvent(ev, s.timeFormat)
+			s.send(dto.WSServerMessage{Type: dto.WSEvent, Event: &e})
+		}
+	}
+}
+
+func (s *wsSession) readLoop() {
+	s.conn.SetReadLimit(wsMaxMessageSize)
+	_ = s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg dto.WSClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			s.sendError("", "validation_error", "invalid message: "+err.Error())
+			continue
+		}
+		s.handle(msg)
+	}
+}
+
+func (s *wsSession) handle(msg dto.WSClientMessage) {
+	switch msg.Type {
+	case dto.WSSubscribe:
+		if len(msg.DeviceIDs) == 0 {
+			s.sendError(msg.ID, "validation_error", "device_ids is required")
+			return
+		}
+		s.mu.Lock()
+		for _, id := range msg.DeviceIDs {
+			if len(s.devices) >= wsMaxDevices {
+				break
+			}
+			s.devices[id] = struct{}{}
+		}
+		s.mu.Unlock()
+		s.send(dto.WSServerMessage{Type: dto.WSAck, ID: msg.ID, Subscriptions: s.subscriptions()})
+	case dto.WSUnsubscribe:
+		s.mu.Lock()
+		for _, id := range msg.DeviceIDs {
+			delete(s.devices, id)
+		}
+		s.mu.Unlock()
+		s.send(dto.WSServerMessage{Type: dto.WSAck, ID: msg.ID, Subscriptions: s.subscriptions()})
+	case dto.WSCommand:
+		s.command(msg)
+	default:
+		s.sendError(msg.ID, "validation_error", "unknown message type")
+	}
+}
+
+func (s *wsSession) command(msg dto.WSClientMessage) {
+	ctx, cancel := context.WithTimeout(context.Background(), wsCommandTimeout)
+	defer cancel()
+	var run func(context.Context, int64) (*models.Device, error)
+	switch msg.Command {
+	case dto.WSCheckOut:
+		run = s.svc.CheckOut
+	case dto.WSCheckIn:
+		run = s.svc.CheckIn
+	default:
+		s.sendError(msg.ID, "validation_error", "unknown command")
+		return
+	}
+	d, err := run(ctx, msg.DeviceID)
+	if err != nil {
+		_, code := errorCode(err)
+		s.sendError(msg.ID, code, err.Error())
+		return
+	}
+	res := dto.FromModel(d, s.timeFormat)
+	s.send(dto.WSServerMessage{Type: dto.WSAck, ID: msg.ID, Device: &res})
+}
+
+func (s *wsSession) subscriptions() []int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]int64, 0, len(s.devices))
+	for id := range s.devices {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func (s *wsSession) sendError(id, code, message string) {
+	s.send(dto.WSServerMessage{Type: dto.WSError, ID: id, Error: &dto.WSErrorBody{Code: code, Message: message}})
+}
+
+func (s *wsSession) send(msg dto.WSServerMessage) {
+	select {
+	case <-s.done:
+	case s.out <- msg:
+	default:
+		s.close(websocket.ClosePolicyViolation, "slow consumer")
+	}
+}
+
+func (s *wsSession) close(code int, text string) {
+	s.closeOnce.Do(func() {
+		s.closeCode, s.closeText = code, text
+		close(s.done)
+	})
+}
+
+func (s *wsSession) writeLoop() {
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+	defer s.conn.Close()
+	for {
+		select {
+		case msg := <-s.out:
+			_ = s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := s.conn.WriteJSON(msg); err != nil {
+				s.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ping.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				s.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-s.done:
+			_ = s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(s.closeCode, s.closeText), time.Now().Add(wsWriteWait))
+			return
+		}
+	}
+}
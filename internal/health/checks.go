@@ -0,0 +1,43 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func Database(db *gorm.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+func Migrations(db *gorm.DB, models ...any) CheckFunc {
+	return func(ctx context.Context) error {
+		m := db.WithContext(ctx).Migrator()
+		for _, model := range models {
+			if !m.HasTable(model) {
+				return fmt.Errorf("missing table for %T", model)
+			}
+		}
+		return nil
+	}
+}
+
+func DiskSpace(dir string, minFreeBytes uint64) CheckFunc {
+	return func(ctx context.Context) error {
+		free, err := freeBytes(dir)
+		if err != nil {
+			return err
+		}
+		if free < minFreeBytes {
+			return fmt.Errorf("only %d bytes free in %s, need %d", free, dir, minFreeBytes)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+const (
+	StatusOK           = "ok"
+	StatusFail         = "fail"
+	StatusShuttingDown = "shutting_down"
+)
+
+type Status struct {
+	ready   atomic.Bool
+	timeout time.Duration
+	mu      sync.RWMutex
+	checks  []check
+}
+
+func NewStatus() *Status {
+	s := &Status{timeout: 2 * time.Second}
+	s.ready.Store(true)
+	return s
+}
+
+func (s *Status) Ready() bool     { return s.ready.Load() }
+func (s *Status) SetReady(v bool) { s.ready.Store(v) }
+
+func (s *Status) SetTimeout(d time.Duration) {
+	if d > 0 {
+		s.timeout = d
+	}
+}
+
+// Register adds a readiness check, replacing any existing check with the same name.
+func (s *Status) Register(name string, fn CheckFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.checks {
+		if s.checks[i].name == name {
+			s.checks[i].fn = fn
+			return
+		}
+	}
+	s.checks = append(s.checks, check{name: name, fn: fn})
+}
+
+// Check runs every registered check concurrently, each bounded by the configured timeout.
+func (s *Status) Check(ctx context.Context) Report {
+	s.mu.RLock()
+	checks := append([]check(nil), s.checks...)
+	s.mu.RUnlock()
+
+	rep := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = s.run(ctx, c.fn)
+		}()
+	}
+	wg.Wait()
+	for i, c := range checks {
+		rep.Checks[c.name] = results[i]
+		if results[i].Status != StatusOK {
+			rep.Status = StatusFail
+		}
+	}
+	if !s.Ready() {
+		rep.Status = StatusShuttingDown
+	}
+	return rep
+}
+
+func (s *Status) run(ctx context.Context, fn CheckFunc) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() { errCh <- fn(ctx) }()
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	res := CheckResult{Status: StatusOK, LatencyMS: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+	return res
+}
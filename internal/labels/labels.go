@@ -0,0 +1,198 @@
+// Package labels renders printable device labels: a QR code linking to the
+// device next to its name, brand and ID.
+package labels
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-pdf/fpdf"
+	"go-backend/internal/models"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"rsc.io/qr"
+)
+
+const (
+	// module is the size of a QR module in PNG and SVG labels, in pixels.
+	module = 4
+	// quiet is the white border around a QR code, in modules.
+	quiet = 4
+	// textScale enlarges the 7x13 bitmap font of PNG labels.
+	textScale = 2
+	// textWidth is the room for text right of the QR code, in pixels.
+	textWidth = 300
+	// maxChars is how many characters of a line fit in textWidth.
+	maxChars = textWidth / (7 * textScale)
+)
+
+// PDF sheets are A4 with 3 x 8 labels of 70 x 37 mm.
+const (
+	sheetColumns = 3
+	sheetRows    = 8
+	labelWidth   = 70.0
+	labelHeight  = 37.0
+	labelMargin  = 3.0
+	sheetLeft    = (210 - sheetColumns*labelWidth) / 2
+	sheetTop     = (297 - sheetRows*labelHeight) / 2
+)
+
+type Renderer struct{ deviceURL string }
+
+// NewRenderer encodes deviceURL, with {id} replaced by the device ID, in the
+// QR code of each label.
+func NewRenderer(deviceURL string) *Renderer { return &Renderer{deviceURL: deviceURL} }
+
+// URL is what the QR code on the label of a device links to.
+func (r *Renderer) URL(id int64) string {
+	return strings.ReplaceAll(r.deviceURL, "{id}", strconv.FormatInt(id, 10))
+}
+
+func (r *Renderer) code(d *models.Device) (*qr.Code, error) {
+	return qr.Encode(r.URL(d.ID), qr.M)
+}
+
+func lines(d *models.Device) []string {
+	return []string{d.Name, d.Brand, "ID " + strconv.FormatInt(d.ID, 10)}
+}
+
+// PNG writes the label of a device as a PNG image.
+func (r *Renderer) PNG(w io.Writer, d *models.Device) error {
+	code, err := r.code(d)
+	if err != nil {
+		return err
+	}
+	side := (code.Size + 2*quiet) * module
+	img := image.NewRGBA(image.Rect(0, 0, side+textWidth, side))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if code.Black(x, y) {
+				px := image.Rect(x+quiet, y+quiet, x+quiet+1, y+quiet+1)
+				px.Min, px.Max = px.Min.Mul(module), px.Max.Mul(module)
+				draw.Draw(img, px, image.Black, image.Point{}, draw.Src)
+			}
+		}
+	}
+	lineHeight := 13 * textScale * 3 / 2
+	top := (side - len(lines(d))*lineHeight) / 2
+	for i, line := range lines(d) {
+		drawText(img, image.Pt(side, top+i*lineHeight), truncate(line, maxChars))
+	}
+	return png.Encode(w, img)
+}
+
+// drawText draws s with its top left corner at p, enlarged by textScale.
+func drawText(dst draw.Image, p image.Point, s string) {
+	face := basicfont.Face7x13
+	small := image.NewAlpha(image.Rect(0, 0, font.MeasureString(face, s).Ceil(), face.Height))
+	(&font.Drawer{Dst: small, Src: image.Opaque, Face: face, Dot: fixed.P(0, face.Ascent)}).DrawString(s)
+	scaled := image.Rect(0, 0, small.Rect.Dx()*textScale, small.Rect.Dy()*textScale).Add(p)
+	mask := image.NewAlpha(scaled)
+	draw.NearestNeighbor.Scale(mask, scaled, small, small.Bounds(), draw.Src, nil)
+	draw.DrawMask(dst, scaled, image.Black, image.Point{}, mask, scaled.Min, draw.Over)
+}
+
+// SVG writes the label of a device as an SVG image of the same size as the
+// PNG one.
+func (r *Renderer) SVG(w io.Writer, d *models.Device) error {
+	code, err := r.code(d)
+	if err != nil {
+		return err
+	}
+	side := (code.Size + 2*quiet) * module
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %[1]d %[2]d">`, side+textWidth, side)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/>`, side+textWidth, side)
+	b.WriteString(`<path fill="#000" d="`)
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if code.Black(x, y) {
+				fmt.Fprintf(&b, "M%d %dh%dv%dh-%dz", (x+quiet)*module, (y+quiet)*module, module, module, module)
+			}
+		}
+	}
+	b.WriteString(`"/>`)
+	lineHeight := 13 * textScale * 3 / 2
+	top := (side - len(lines(d))*lineHeight) / 2
+	for i, line := range lines(d) {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-family="monospace" font-size="%d">`, side, top+i*lineHeight+11*textScale, 12*textScale)
+		_ = xml.EscapeText(&b, []byte(truncate(line, maxChars)))
+		b.WriteString(`</text>`)
+	}
+	b.WriteString(`</svg>`)
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// PDF writes A4 sheets with one label per device, filled row by row.
+func (r *Renderer) PDF(w io.Writer, list []models.Device) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	if len(list) == 0 {
+		pdf.AddPage()
+	}
+	for i := range list {
+		slot := i % (sheetColumns * sheetRows)
+		if slot == 0 {
+			pdf.AddPage()
+		}
+		x := sheetLeft + float64(slot%sheetColumns)*labelWidth
+		y := sheetTop + float64(slot/sheetColumns)*labelHeight
+		code, err := r.code(&list[i])
+		if err != nil {
+			return err
+		}
+		side := labelHeight - 2*labelMargin
+		mod := side / float64(code.Size+2*quiet)
+		pdf.SetFillColor(0, 0, 0)
+		for cy := 0; cy < code.Size; cy++ {
+			for cx := 0; cx < code.Size; cx++ {
+				if code.Black(cx, cy) {
+					pdf.Rect(x+labelMargin+float64(cx+quiet)*mod, y+labelMargin+float64(cy+quiet)*mod, mod, mod, "F")
+				}
+			}
+		}
+		textX, textW := x+labelMargin+side, labelWidth-side-3*labelMargin
+		for j, line := range lines(&list[i]) {
+			style, size := "", 9.0
+			if j == 0 {
+				style, size = "B", 10.0
+			}
+			pdf.SetFont("Helvetica", style, size)
+			pdf.SetXY(textX, y+labelMargin+8+float64(j)*6)
+			pdf.CellFormat(textW, 5, fitWidth(pdf, tr(line), textW), "", 0, "L", false, 0, "")
+		}
+	}
+	return pdf.Output(w)
+}
+
+// fitWidth shortens s until it fits in width at the current font.
+func fitWidth(pdf *fpdf.Fpdf, s string, width float64) string {
+	if pdf.GetStringWidth(s) <= width {
+		return s
+	}
+	for len(s) > 0 && pdf.GetStringWidth(s+"...") > width {
+		s = s[:len(s)-1]
+	}
+	return s + "..."
+}
+
+// truncate shortens s to at most n characters.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:n-3]) + "..."
+}
@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go-backend/config"
+	apperror "go-backend/pkg/error"
+)
+
+// APIKeyHeader carries one of the configured auth.api_keys.
+const APIKeyHeader = "X-API-Key"
+
+// Auth admits requests that present one of the configured API keys in the
+// X-API-Key header or an HS256 bearer token signed with the JWT secret, and
+// answers others with 401. With auth disabled every request is admitted.
+func Auth(cfg config.AuthConfig) gin.HandlerFunc {
+	if !cfg.Enabled {
+		return func(*gin.Context) {}
+	}
+	keys := make([][]byte, len(cfg.APIKeys))
+	for i, k := range cfg.APIKeys {
+		keys[i] = []byte(k)
+	}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	secret := []byte(cfg.JWTSecret)
+	return func(c *gin.Context) {
+		if key := c.GetHeader(APIKeyHeader); key != "" {
+			for _, k := range keys {
+				if subtle.ConstantTimeCompare([]byte(key), k) == 1 {
+					return
+				}
+			}
+		}
+		if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok && len(secret) > 0 {
+			_, err := parser.Parse(token, func(*jwt.Token) (any, error) { return secret, nil })
+			if err == nil {
+				return
+			}
+		}
+		c.Header("WWW-Authenticate", `Bearer realm="devices"`)
+		apperror.JSONError(c, http.StatusUnauthorized, "unauthorized", "missing or invalid credentials", nil)
+	}
+}
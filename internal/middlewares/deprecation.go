@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecation marks responses as coming from a deprecated API version with
+// the Deprecation (RFC 9745) and Sunset (RFC 8594) headers, and links to the
+// same path under successor. Zero times leave their header out.
+func Deprecation(at, sunset time.Time, successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		if !at.IsZero() {
+			h.Set("Deprecation", "@"+strconv.FormatInt(at.Unix(), 10))
+		}
+		if !sunset.IsZero() {
+			h.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+		if successor != "" {
+			h.Add("Link", "<"+successor+c.Request.URL.Path+`>; rel="successor-version"`)
+		}
+	}
+}
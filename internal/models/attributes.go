@@ -0,0 +1,197 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// Attributes is category specific device metadata, stored as a JSON object.
+type Attributes map[string]any
+
+func (a Attributes) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return "{}", nil
+	}
+	b, err := json.Marshal(a)
+	return string(b), err
+}
+
+// Clone returns a deep copy of a; JSON objects and arrays nested in it are
+// copied too.
+func (a Attributes) Clone() Attributes {
+	if a == nil {
+		return nil
+	}
+	return cloneJSON(map[string]any(a)).(map[string]any)
+}
+
+func cloneJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = cloneJSON(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = cloneJSON(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func (a *Attributes) Scan(src interface{}) error {
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("unsupported Scan type %T", src)
+	}
+	*a = nil
+	return json.Unmarshal(raw, a)
+}
+
+// CategorySchema is the JSON Schema the attributes of devices in a category
+// must satisfy.
+type CategorySchema struct {
+	Category  string    `gorm:"primaryKey;column:category"`
+	Schema    string    `gorm:"column:schema;not null"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+// AttributesError lists why attributes do not satisfy their category schema.
+type AttributesError struct {
+	Category string
+	Problems []string
+}
+
+func (e *AttributesError) Error() string {
+	return fmt.Sprintf("attributes do not match the %s schema", e.Category)
+}
+
+var ErrInvalidSchema = errors.New("invalid JSON Schema")
+
+// CompileSchema parses and compiles a JSON Schema document.
+func CompileSchema(schema string) (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(schema))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	c := jsonschema.NewCompiler()
+	// Schemas are self-contained; $ref must not reach files or the network.
+	c.UseLoader(jsonschema.SchemeURLLoader{})
+	if err := c.AddResource("category.json", doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	s, err := c.Compile("category.json")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	return s, nil
+}
+
+// compiledSchema is a compiled category schema and the document it was
+// compiled from.
+type compiledSchema struct {
+	source string
+	schema *jsonschema.Schema
+}
+
+// compiledSchemas caches compiled schemas by category. An entry is only used
+// while its source matches the stored schema, so a schema replaced by another
+// process is recompiled on its next use.
+var compiledSchemas sync.Map
+
+// ForgetSchema drops the compiled schema of category, after it was replaced
+// or deleted.
+func ForgetSchema(category string) {
+	compiledSchemas.Delete(category)
+}
+
+// compiled returns the compiled schema s, compiling it on first use.
+func (s *CategorySchema) compiled() (*jsonschema.Schema, error) {
+	if v, ok := compiledSchemas.Load(s.Category); ok {
+		if c := v.(compiledSchema); c.source == s.Schema {
+			return c.schema, nil
+		}
+	}
+	compiled, err := CompileSchema(s.Schema)
+	if err != nil {
+		return nil, err
+	}
+	compiledSchemas.Store(s.Category, compiledSchema{source: s.Schema, schema: compiled})
+	return compiled, nil
+}
+
+// Validate checks attrs against the schema s of category.
+func (s *CategorySchema) Validate(attrs Attributes) error {
+	compiled, err := s.compiled()
+	if err != nil {
+		return err
+	}
+	// Round-trip through JSON so numbers have the types the validator expects.
+	b, err := json.Marshal(attrs)
+	if err != nil {
+		return err
+	}
+	if attrs == nil {
+		b = []byte("{}")
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	err = compiled.Validate(doc)
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return err
+	}
+	out := &AttributesError{Category: s.Category}
+	for _, u := range verr.BasicOutput().Errors {
+		if u.Error == nil || len(u.Error.String()) == 0 {
+			continue
+		}
+		loc := u.InstanceLocation
+		if loc == "" {
+			loc = "/"
+		}
+		out.Problems = append(out.Problems, loc+": "+u.Error.String())
+	}
+	return out
+}
+
+var ErrInvalidAttributePath = errors.New("attribute paths are dot separated names of letters, digits, _ and -")
+
+// AttributePath turns a dotted attribute name such as display.size into a
+// SQLite JSON path.
+func AttributePath(name string) (string, error) {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, seg := range strings.Split(name, ".") {
+		if seg == "" || strings.IndexFunc(seg, func(r rune) bool {
+			return !(r == '_' || r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+		}) >= 0 {
+			return "", ErrInvalidAttributePath
+		}
+		b.WriteString(`."` + seg + `"`)
+	}
+	return b.String(), nil
+}
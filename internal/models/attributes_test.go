@@ -0,0 +1,39 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCategorySchema_CompiledOnce(t *testing.T) {
+	s := &CategorySchema{Category: "cache-test", Schema: `{"type":"object","required":["size"]}`}
+	first, err := s.compiled()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := s.compiled()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != again {
+		t.Fatalf("schema compiled twice")
+	}
+	// A changed schema is recompiled even without ForgetSchema.
+	s.Schema = `{"type":"object"}`
+	changed, err := s.compiled()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed == first {
+		t.Fatalf("stale compiled schema used after change")
+	}
+	if err := s.Validate(Attributes{}); err != nil {
+		t.Fatalf("expected valid attributes, got %v", err)
+	}
+	ForgetSchema(s.Category)
+	s.Schema = `{"type":"object","required":["size"]}`
+	var aerr *AttributesError
+	if err := s.Validate(Attributes{}); !errors.As(err, &aerr) {
+		t.Fatalf("expected AttributesError, got %v", err)
+	}
+}
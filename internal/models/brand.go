@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Brand is the canonical name of a manufacturer. Devices reference it by ID
+// and keep its name in their brand column, so filters and responses see one
+// spelling per brand.
+type Brand struct {
+	ID        int64     `gorm:"primaryKey;column:id"`
+	Name      string    `gorm:"column:name;not null"`
+	Key       string    `gorm:"column:key;not null;uniqueIndex"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+// BrandAlias is another spelling of a brand, such as "Apple Inc." for Apple.
+type BrandAlias struct {
+	Key     string `gorm:"primaryKey;column:key"`
+	Alias   string `gorm:"column:alias;not null"`
+	BrandID int64  `gorm:"column:brand_id;not null;index"`
+}
+
+// BrandWithAliases is a brand together with its aliases and the number of
+// devices referencing it.
+type BrandWithAliases struct {
+	Brand
+	Aliases []string
+	Devices int64
+}
+
+var (
+	ErrBrandConflict = errors.New("brand name or alias already belongs to another brand")
+	ErrBrandInUse    = errors.New("brand is still referenced by devices")
+	ErrInvalidBrand  = errors.New("brand names must not be empty")
+	ErrUnknownBrand  = errors.New("unknown brand; register it under /brands first")
+)
+
+// BrandKey is the form brand names and aliases are matched in: lower case
+// with runs of white space collapsed, so "apple " matches "Apple".
+func BrandKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
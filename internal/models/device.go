@@ -22,18 +22,59 @@ func (s State) Valid() bool {
 }
 
 type Device struct {
-	ID        int64         `json:"id" gorm:"primaryKey;column:id"`
-	Name      string        `json:"name" gorm:"column:name;index:idx_devices_brand"`
-	Brand     string        `json:"brand" gorm:"column:brand;index:idx_devices_brand"`
-	State     State         `json:"state" gorm:"column:state;index:idx_devices_state"`
-	CreatedAt FormattedTime `json:"created_at" gorm:"column:created_at;type:text"`
+	ID           int64         `json:"id" gorm:"primaryKey;column:id"`
+	Name         string        `json:"name" gorm:"column:name;index:idx_devices_brand"`
+	Brand        string        `json:"brand" gorm:"column:brand;index:idx_devices_brand"`
+	BrandID      *int64        `json:"brand_id" gorm:"column:brand_id;index"`
+	State        State         `json:"state" gorm:"column:state;index:idx_devices_state"`
+	SerialNumber *string       `json:"serial_number" gorm:"column:serial_number;uniqueIndex"`
+	AssetTag     *string       `json:"asset_tag" gorm:"column:asset_tag;uniqueIndex"`
+	Category     string        `json:"category" gorm:"column:category;not null;default:'';index:idx_devices_category"`
+	Attributes   Attributes    `json:"attributes" gorm:"column:attributes;type:text;not null;default:'{}'"`
+	CreatedAt    FormattedTime `json:"created_at" gorm:"column:created_at;type:text"`
+	UpdatedAt    FormattedTime `json:"updated_at" gorm:"column:updated_at;type:text"`
+}
+
+// Clone returns a copy of d that shares no attributes or pointer fields
+// with it.
+func (d Device) Clone() Device {
+	c := d
+	c.Attributes = d.Attributes.Clone()
+	c.BrandID = clonePtr(d.BrandID)
+	c.SerialNumber = clonePtr(d.SerialNumber)
+	c.AssetTag = clonePtr(d.AssetTag)
+	return c
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// DeviceFilter selects devices; empty fields match every device. Tags match
+// devices carrying any of them, or all of them when AllTags is set.
+// Attributes maps dotted attribute paths to the value they must have.
+type DeviceFilter struct {
+	Brand      string
+	State      string
+	Category   string
+	Tags       []string
+	AllTags    bool
+	Attributes map[string]string
 }
 
 var (
-	ErrInvalidState        = errors.New("invalid state")
-	ErrCannotUpdateCreated = errors.New("creation time cannot be updated")
-	ErrCannotUpdateFields  = errors.New("name/brand cannot be updated while in use")
-	ErrCannotDeleteInUse   = errors.New("in-use devices cannot be deleted")
+	ErrInvalidState           = errors.New("invalid state")
+	ErrCannotUpdateCreated    = errors.New("creation time cannot be updated")
+	ErrCannotUpdateFields     = errors.New("name/brand cannot be updated while in use")
+	ErrCannotDeleteInUse      = errors.New("in-use devices cannot be deleted")
+	ErrNotAvailable           = errors.New("device is not available")
+	ErrNotInUse               = errors.New("device is not in use")
+	ErrConcurrentModification = errors.New("device was modified concurrently")
+	ErrDeviceNotFound         = errors.New("device not found")
 )
 
 func (d *Device) ValidateNew() error {
@@ -46,5 +87,8 @@ func (d *Device) ValidateNew() error {
 	if d.CreatedAt.IsZero() {
 		d.CreatedAt = NowFormattedTime()
 	}
+	if d.UpdatedAt.IsZero() {
+		d.UpdatedAt = d.CreatedAt
+	}
 	return nil
 }
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// DeviceChange records a device as one create, update, patch, delete, state
+// change or lease expiry left it. It is written in the same transaction as
+// the change and, unlike the webhook outbox, is never pruned.
+type DeviceChange struct {
+	ID        int64     `gorm:"primaryKey;column:id"`
+	DeviceID  int64     `gorm:"column:device_id;index"`
+	Type      string    `gorm:"column:type;not null"`
+	Payload   string    `gorm:"column:payload;type:text"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxIdentifierLength bounds serial numbers and asset tags.
+const MaxIdentifierLength = 128
+
+var ErrInvalidIdentifier = errors.New("serial numbers and asset tags must be at most 128 characters without control characters")
+
+// DuplicateError reports a serial number or asset tag that already belongs
+// to another device.
+type DuplicateError struct {
+	Field    string
+	Value    string
+	DeviceID int64
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("%s %q already belongs to device %d", e.Field, e.Value, e.DeviceID)
+}
+
+// NormalizeIdentifier trims a serial number or asset tag; a blank one is
+// absent.
+func NormalizeIdentifier(s *string) (*string, error) {
+	if s == nil {
+		return nil, nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(v) > MaxIdentifierLength || strings.ContainsFunc(v, unicode.IsControl) {
+		return nil, ErrInvalidIdentifier
+	}
+	return &v, nil
+}
+
+// NormalizeIdentifiers normalizes the serial number and asset tag of d.
+func (d *Device) NormalizeIdentifiers() error {
+	var err error
+	if d.SerialNumber, err = NormalizeIdentifier(d.SerialNumber); err != nil {
+		return err
+	}
+	d.AssetTag, err = NormalizeIdentifier(d.AssetTag)
+	return err
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type LeaseStatus string
+
+const (
+	LeaseActive   LeaseStatus = "active"
+	LeaseReleased LeaseStatus = "released"
+	LeaseExpired  LeaseStatus = "expired"
+)
+
+// Lease holds a device in use for Holder until ExpiresAt unless renewed. A
+// device has at most one active lease: taking one moves the device from
+// available to in-use, and the lease ends whenever the device leaves in-use.
+type Lease struct {
+	ID        int64       `gorm:"primaryKey;column:id"`
+	DeviceID  int64       `gorm:"column:device_id;not null;index"`
+	Holder    string      `gorm:"column:holder;not null"`
+	Status    LeaseStatus `gorm:"column:status;not null;index:idx_leases_due"`
+	ExpiresAt time.Time   `gorm:"column:expires_at;not null;index:idx_leases_due"`
+	CreatedAt time.Time   `gorm:"column:created_at"`
+	EndedAt   *time.Time  `gorm:"column:ended_at"`
+}
+
+var (
+	ErrInvalidLease   = errors.New("a lease needs a holder and a ttl that is positive and at most the configured maximum")
+	ErrLeaseNotActive = errors.New("lease has already been released or expired")
+)
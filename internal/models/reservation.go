@@ -0,0 +1,47 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type ReservationStatus string
+
+const (
+	ReservationScheduled ReservationStatus = "scheduled"
+	ReservationActive    ReservationStatus = "active"
+	ReservationCompleted ReservationStatus = "completed"
+	ReservationCancelled ReservationStatus = "cancelled"
+)
+
+// Reservation books a device for holder from StartsAt until EndsAt.
+// Scheduled and active reservations of a device never overlap. CheckedOut
+// records whether the reservation moved the device to in-use when it
+// started, and so has to return it when it ends.
+type Reservation struct {
+	ID         int64             `gorm:"primaryKey;column:id"`
+	DeviceID   int64             `gorm:"column:device_id;not null;index:idx_reservations_device"`
+	Holder     string            `gorm:"column:holder;not null"`
+	StartsAt   time.Time         `gorm:"column:starts_at;not null;index:idx_reservations_device"`
+	EndsAt     time.Time         `gorm:"column:ends_at;not null"`
+	Status     ReservationStatus `gorm:"column:status;not null;index"`
+	CheckedOut bool              `gorm:"column:checked_out;not null;default:false"`
+	CreatedAt  time.Time         `gorm:"column:created_at"`
+}
+
+// ReservationConflictError names a reservation whose window overlaps the
+// requested one.
+type ReservationConflictError struct {
+	ReservationID int64
+}
+
+func (e *ReservationConflictError) Error() string {
+	return fmt.Sprintf("the device is already reserved in this window by reservation %d", e.ReservationID)
+}
+
+var (
+	ErrInvalidReservation = errors.New("a reservation needs a holder and must end after it starts and in the future")
+	ErrReservationEnded   = errors.New("reservation has already ended")
+	ErrInvalidWindow      = errors.New("the window must end after it starts")
+)
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// StatsInterval buckets devices by the day, week (starting Monday) or month
+// of their creation.
+type StatsInterval string
+
+const (
+	IntervalDay   StatsInterval = "day"
+	IntervalWeek  StatsInterval = "week"
+	IntervalMonth StatsInterval = "month"
+)
+
+// StatsQuery selects the dimensions devices are counted by and the devices
+// counted. From and To bound the creation day, inclusively, when non-zero.
+type StatsQuery struct {
+	ByBrand  bool
+	ByState  bool
+	Interval StatsInterval
+
+	Brand    string
+	State    string
+	From, To time.Time
+}
+
+// DeviceCount is the number of devices in one group; dimensions that were
+// not grouped by are empty. Period is YYYY-MM-DD, or YYYY-MM for months, and
+// weeks are named by their Monday.
+type DeviceCount struct {
+	Brand  string
+	State  string
+	Period string
+	Count  int64
+}
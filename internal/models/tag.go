@@ -0,0 +1,61 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Tag is a label shared by any number of devices. Names are normalized and
+// unique within the deployment.
+type Tag struct {
+	ID   int64  `json:"id" gorm:"primaryKey;column:id"`
+	Name string `json:"name" gorm:"column:name;not null;uniqueIndex"`
+}
+
+// DeviceTag links a device to a tag.
+type DeviceTag struct {
+	DeviceID int64 `gorm:"primaryKey;column:device_id"`
+	TagID    int64 `gorm:"primaryKey;column:tag_id;index"`
+}
+
+// TagCount is a tag with the number of devices carrying it.
+type TagCount struct {
+	Name    string
+	Devices int64
+}
+
+const maxTagLength = 64
+
+var ErrInvalidTag = errors.New("tags must be 1 to 64 letters, digits or - _ . :")
+
+// NormalizeTag lower-cases name and joins its words with dashes, so "Test
+// Lab" and "test-lab" are the same tag.
+func NormalizeTag(name string) (string, error) {
+	n := strings.ToLower(strings.Join(strings.Fields(name), "-"))
+	if n == "" || len(n) > maxTagLength {
+		return "", ErrInvalidTag
+	}
+	for _, r := range n {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_.:", r) {
+			return "", ErrInvalidTag
+		}
+	}
+	return n, nil
+}
+
+// NormalizeTags normalizes names and drops duplicates, keeping their order.
+func NormalizeTags(names []string) ([]string, error) {
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		n, err := NormalizeTag(name)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(out, n) {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
@@ -3,12 +3,43 @@ package models
 import (
 	"database/sql/driver"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
 
 const DbTimeLayout = "02.01.2006 15:04:05"
 
+// TimeFormat selects how timestamps are rendered in API responses.
+type TimeFormat string
+
+const (
+	TimeRFC3339 TimeFormat = "rfc3339"
+	TimeEpoch   TimeFormat = "epoch"
+	TimeLegacy  TimeFormat = "legacy"
+)
+
+func (f TimeFormat) Valid() bool {
+	switch f {
+	case TimeRFC3339, TimeEpoch, TimeLegacy:
+		return true
+	}
+	return false
+}
+
+// ParseTime accepts every format the API renders: RFC 3339, the legacy
+// DbTimeLayout and Unix seconds.
+func ParseTime(s string) (time.Time, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(n, 0).UTC(), nil
+	}
+	var ft FormattedTime
+	if err := ft.parseString(s); err != nil {
+		return time.Time{}, err
+	}
+	return ft.Time, nil
+}
+
 type FormattedTime struct{ time.Time }
 
 func NewFormattedTime(t time.Time) FormattedTime { return FormattedTime{t.UTC()} }
@@ -18,6 +49,9 @@ func (ft FormattedTime) Value() (driver.Value, error) { return ft.Time.UTC().For
 
 func (ft *FormattedTime) Scan(src interface{}) error {
 	switch v := src.(type) {
+	case nil:
+		ft.Time = time.Time{}
+		return nil
 	case time.Time:
 		ft.Time = v.UTC()
 		return nil
@@ -59,7 +93,7 @@ func (ft *FormattedTime) UnmarshalJSON(b []byte) error {
 		ft.Time = time.Time{}
 		return nil
 	}
-	t, err := time.ParseInLocation(DbTimeLayout, s, time.UTC)
+	t, err := ParseTime(s)
 	if err != nil {
 		return err
 	}
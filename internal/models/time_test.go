@@ -35,3 +35,23 @@ func TestFormattedTime_JSON(t *testing.T) {
 		t.Fatalf("unexpected json: %s", string(b))
 	}
 }
+
+func TestParseTime_Formats(t *testing.T) {
+	want := time.Date(2025, 12, 14, 20, 1, 13, 0, time.UTC)
+	for _, in := range []string{"14.12.2025 20:01:13", "2025-12-14T20:01:13Z", "2025-12-14T21:01:13+01:00", "1765742473"} {
+		got, err := ParseTime(in)
+		if err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if !got.Equal(want) {
+			t.Fatalf("%s: got %v", in, got)
+		}
+	}
+	var ft FormattedTime
+	if err := ft.UnmarshalJSON([]byte("1765742473")); err != nil || !ft.Equal(NewFormattedTime(want)) {
+		t.Fatalf("epoch json: %v %v", ft, err)
+	}
+	if _, err := ParseTime("yesterday"); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryDead      DeliveryStatus = "dead"
+)
+
+type WebhookSubscription struct {
+	ID         int64     `json:"id" gorm:"primaryKey;column:id"`
+	URL        string    `json:"url" gorm:"column:url;not null"`
+	Secret     string    `json:"-" gorm:"column:secret;not null"`
+	EventTypes string    `json:"event_types" gorm:"column:event_types"`
+	Active     bool      `json:"active" gorm:"column:active;index"`
+	CreatedAt  time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// OutboxEvent is written in the same transaction as the device change it
+// describes and later fanned out to webhook deliveries.
+type OutboxEvent struct {
+	ID          int64      `gorm:"primaryKey;column:id"`
+	EventType   string     `gorm:"column:event_type;not null"`
+	DeviceID    int64      `gorm:"column:device_id"`
+	Payload     string     `gorm:"column:payload;type:text"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+	ProcessedAt *time.Time `gorm:"column:processed_at;index"`
+}
+
+type WebhookDelivery struct {
+	ID             int64          `gorm:"primaryKey;column:id"`
+	SubscriptionID int64          `gorm:"column:subscription_id;index"`
+	OutboxEventID  int64          `gorm:"column:outbox_event_id"`
+	EventType      string         `gorm:"column:event_type"`
+	Status         DeliveryStatus `gorm:"column:status;index:idx_webhook_deliveries_due"`
+	Attempts       int            `gorm:"column:attempts"`
+	NextAttemptAt  time.Time      `gorm:"column:next_attempt_at;index:idx_webhook_deliveries_due"`
+	LastStatusCode int            `gorm:"column:last_status_code"`
+	LastError      string         `gorm:"column:last_error"`
+	DeliveredAt    *time.Time     `gorm:"column:delivered_at"`
+	CreatedAt      time.Time      `gorm:"column:created_at"`
+	UpdatedAt      time.Time      `gorm:"column:updated_at"`
+}
+
+var (
+	ErrInvalidWebhookURL   = errors.New("webhook url must be an absolute http or https url")
+	ErrWebhookHostDenied   = errors.New("webhook url targets a host outside webhooks.allowed_hosts or an internal address")
+	ErrInvalidEventType    = errors.New("unknown event type")
+	ErrDeliveryNotRetrying = errors.New("only dead deliveries can be retried")
+)
+
+// Types returns the subscribed event types; empty means all events.
+func (s *WebhookSubscription) Types() []string {
+	if s.EventTypes == "" {
+		return []string{}
+	}
+	return strings.Split(s.EventTypes, ",")
+}
+
+func (s *WebhookSubscription) Matches(eventType string) bool {
+	types := s.Types()
+	return len(types) == 0 || slices.Contains(types, eventType)
+}
@@ -0,0 +1,193 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Description          string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+}
+
+// Describer is implemented by types whose JSON encoding cannot be derived
+// from their Go type, such as values with several encodings.
+type Describer interface {
+	OpenAPISchema() *Schema
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	describerType = reflect.TypeOf((*Describer)(nil)).Elem()
+)
+
+// Partial documents a response body of v's type in which every property is
+// optional, such as one trimmed to a sparse fieldset.
+func Partial(v any) any { return partial{reflect.TypeOf(v)} }
+
+type partial struct{ t reflect.Type }
+
+// Binary documents a response body that is not JSON, such as an image.
+type Binary struct{}
+
+func (Binary) OpenAPISchema() *Schema { return &Schema{Type: "string", Format: "binary"} }
+
+func (d *Document) responseSchema(body any) *Schema {
+	if p, ok := body.(partial); ok {
+		return d.partialSchema(p.t)
+	}
+	return d.schemaFor(reflect.TypeOf(body), false)
+}
+
+// partialSchema registers named structs as Partial<Name> components.
+func (d *Document) partialSchema(t reflect.Type) *Schema {
+	switch {
+	case t.Kind() == reflect.Slice:
+		return &Schema{Type: "array", Items: d.partialSchema(t.Elem())}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		name := "Partial" + t.Name()
+		if _, ok := d.Components.Schemas[name]; !ok {
+			s := *d.resolve(d.schemaFor(t, false))
+			s.Required = nil
+			d.Components.Schemas[name] = &s
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return d.schemaFor(t, false)
+}
+
+// schemaFor returns the schema for t, registering named structs as
+// components. Request schemas are closed (additionalProperties: false).
+func (d *Document) schemaFor(t reflect.Type, request bool) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t, nullable = t.Elem(), true
+	}
+	s := d.typeSchema(t, request)
+	if nullable {
+		if s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+	}
+	return s
+}
+
+func (d *Document) typeSchema(t reflect.Type, request bool) *Schema {
+	if t.Implements(describerType) {
+		return reflect.Zero(t).Interface().(Describer).OpenAPISchema()
+	}
+	if t == timeType || (t.Kind() == reflect.Struct && t.ConvertibleTo(timeType)) {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: d.schemaFor(t.Elem(), request)}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		return d.structSchema(t, request)
+	default:
+		return &Schema{}
+	}
+}
+
+func (d *Document) structSchema(t reflect.Type, request bool) *Schema {
+	name := t.Name()
+	if name != "" {
+		if _, ok := d.Components.Schemas[name]; ok {
+			return &Schema{Ref: "#/components/schemas/" + name}
+		}
+		// Reserve the name first so recursive types terminate.
+		d.Components.Schemas[name] = &Schema{}
+	}
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	if request {
+		closed := false
+		s.AdditionalProperties = &closed
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		jsonName, omitempty := jsonField(f)
+		if jsonName == "-" {
+			continue
+		}
+		s.Properties[jsonName] = d.fieldSchema(f, request)
+		binding := f.Tag.Get("binding")
+		if hasRule(binding, "required") || (!request && !omitempty && f.Type.Kind() != reflect.Pointer) {
+			s.Required = append(s.Required, jsonName)
+		}
+	}
+	if name == "" {
+		return s
+	}
+	d.Components.Schemas[name] = s
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (d *Document) fieldSchema(f reflect.StructField, request bool) *Schema {
+	s := d.schemaFor(f.Type, request)
+	binding := f.Tag.Get("binding")
+	if v, ok := ruleValue(binding, "oneof"); ok && s.Ref == "" {
+		s.Enum = strings.Fields(v)
+	}
+	if hasRule(binding, "required") && s.Type == "string" && len(s.Enum) == 0 {
+		one := 1
+		s.MinLength = &one
+	}
+	if format := f.Tag.Get("format"); format != "" && s.Ref == "" {
+		s.Format = format
+	}
+	return s
+}
+
+func jsonField(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "" {
+		return f.Name, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, strings.Contains(opts, "omitempty")
+}
+
+func hasRule(binding, rule string) bool {
+	_, ok := ruleValue(binding, rule)
+	return ok
+}
+
+func ruleValue(binding, rule string) (string, bool) {
+	for _, r := range strings.Split(binding, ",") {
+		name, val, _ := strings.Cut(r, "=")
+		if name == rule {
+			return val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,222 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	JSONContentType        = "application/json"
+	EventStreamContentType = "text/event-stream"
+	MergePatchContentType  = "application/merge-patch+json"
+	JSONPatchContentType   = "application/json-patch+json"
+)
+
+// Operation describes a route in the route table. Params is a struct whose
+// `uri`, `form` and `header` tagged fields become path, query and header
+// parameters, Body is the JSON request DTO and Bodies adds request bodies in
+// other media types.
+type Operation struct {
+	Method      string
+	Path        string
+	ID          string
+	Summary     string
+	Description string
+	Tags        []string
+	Params      any
+	Body        any
+	Bodies      map[string]any
+	Responses   []Response
+	Deprecated  bool
+}
+
+type Response struct {
+	Status      int
+	Description string
+	Body        any
+	ContentType string
+}
+
+type Info struct {
+	Title       string `json:"title" yaml:"title"`
+	Version     string `json:"version" yaml:"version"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type Server struct {
+	URL string `json:"url" yaml:"url"`
+}
+
+type Document struct {
+	OpenAPI    string              `json:"openapi" yaml:"openapi"`
+	Info       Info                `json:"info" yaml:"info"`
+	Servers    []Server            `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths" yaml:"paths"`
+	Components Components          `json:"components" yaml:"components"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas" yaml:"schemas"`
+}
+
+type PathItem map[string]*OperationObject
+
+type OperationObject struct {
+	OperationID string                    `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Summary     string                    `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string                    `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string                  `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Parameters  []Parameter               `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *RequestBody              `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]ResponseObject `json:"responses" yaml:"responses"`
+	Deprecated  bool                      `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name" yaml:"name"`
+	In       string  `json:"in" yaml:"in"`
+	Required bool    `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema   *Schema `json:"schema" yaml:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required" yaml:"required"`
+	Content  map[string]MediaType `json:"content" yaml:"content"`
+}
+
+type ResponseObject struct {
+	Description string               `json:"description" yaml:"description"`
+	Content     map[string]MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema" yaml:"schema"`
+}
+
+// Build generates an OpenAPI 3.0 document from the route table.
+func Build(info Info, ops []Operation) *Document {
+	d := &Document{
+		OpenAPI:    "3.0.3",
+		Info:       info,
+		Paths:      map[string]PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+	for i := range ops {
+		op := &ops[i]
+		p := SpecPath(op.Path)
+		if d.Paths[p] == nil {
+			d.Paths[p] = PathItem{}
+		}
+		d.Paths[p][strings.ToLower(op.Method)] = d.operation(op)
+	}
+	return d
+}
+
+// Operation returns the documented operation for a method and gin route pattern.
+func (d *Document) Operation(method, ginPath string) *OperationObject {
+	return d.Paths[SpecPath(ginPath)][strings.ToLower(method)]
+}
+
+func (d *Document) operation(op *Operation) *OperationObject {
+	o := &OperationObject{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        op.Tags,
+		Parameters:  d.parameters(op.Params),
+		Responses:   map[string]ResponseObject{},
+		Deprecated:  op.Deprecated,
+	}
+	if op.Body != nil || len(op.Bodies) > 0 {
+		o.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{}}
+		if op.Body != nil {
+			o.RequestBody.Content[JSONContentType] = MediaType{Schema: d.schemaFor(reflect.TypeOf(op.Body), true)}
+		}
+		for ct, body := range op.Bodies {
+			o.RequestBody.Content[ct] = MediaType{Schema: d.schemaFor(reflect.TypeOf(body), true)}
+		}
+	}
+	// Responses with the same status document alternative content types.
+	for _, r := range op.Responses {
+		ro, ok := o.Responses[strconv.Itoa(r.Status)]
+		if !ok {
+			ro = ResponseObject{Description: r.Description}
+		}
+		if ro.Description == "" {
+			ro.Description = http.StatusText(r.Status)
+		}
+		if r.Body != nil {
+			if ro.Content == nil {
+				ro.Content = map[string]MediaType{}
+			}
+			ro.Content[r.contentType()] = MediaType{Schema: d.responseSchema(r.Body)}
+		}
+		o.Responses[strconv.Itoa(r.Status)] = ro
+	}
+	return o
+}
+
+// Streaming reports whether the operation responds with an event stream or
+// upgrades the connection, in which case responses cannot be buffered.
+func (o *OperationObject) Streaming() bool {
+	if _, ok := o.Responses[strconv.Itoa(http.StatusSwitchingProtocols)]; ok {
+		return true
+	}
+	for _, r := range o.Responses {
+		if _, ok := r.Content[EventStreamContentType]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Response) contentType() string {
+	if r.ContentType != "" {
+		return r.ContentType
+	}
+	return JSONContentType
+}
+
+func (d *Document) parameters(params any) []Parameter {
+	if params == nil {
+		return nil
+	}
+	t := reflect.TypeOf(params)
+	var out []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			out = append(out, d.parameters(reflect.Zero(f.Type).Interface())...)
+			continue
+		}
+		p := Parameter{Schema: d.fieldSchema(f, false)}
+		if name := f.Tag.Get("uri"); name != "" {
+			p.Name, p.In, p.Required = name, "path", true
+		} else if name := f.Tag.Get("header"); name != "" {
+			p.Name, p.In = name, "header"
+		} else if name := f.Tag.Get("form"); name != "" && name != "-" {
+			p.Name, p.In = name, "query"
+			p.Required = hasRule(f.Tag.Get("binding"), "required")
+		} else {
+			continue
+		}
+		p.Schema.MinLength = nil
+		out = append(out, p)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].In == "path" && out[j].In != "path" })
+	return out
+}
+
+// SpecPath converts a gin route pattern such as /devices/:id to /devices/{id}.
+func SpecPath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, s := range parts {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			parts[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
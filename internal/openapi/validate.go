@@ -0,0 +1,145 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidateJSON decodes body and validates it against s, returning every violation found.
+func (d *Document) ValidateJSON(s *Schema, body []byte) []string {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return []string{"body: invalid JSON: " + err.Error()}
+	}
+	var errs []string
+	d.validate(s, v, "body", &errs)
+	sort.Strings(errs)
+	return errs
+}
+
+// ValidateParam validates a raw path or query parameter value.
+func (d *Document) ValidateParam(s *Schema, name, raw string) []string {
+	var v any = raw
+	switch s.Type {
+	case "integer":
+		v = json.Number(raw)
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return []string{name + ": must be a boolean"}
+		}
+		v = b
+	}
+	var errs []string
+	d.validate(s, v, name, &errs)
+	return errs
+}
+
+func (d *Document) resolve(s *Schema) *Schema {
+	for s.Ref != "" {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+func (d *Document) validate(s *Schema, v any, path string, errs *[]string) {
+	s = d.resolve(s)
+	fail := func(format string, args ...any) { *errs = append(*errs, path+": "+fmt.Sprintf(format, args...)) }
+	if v == nil {
+		if !s.Nullable && s.Type != "" {
+			fail("must not be null")
+		}
+		return
+	}
+	if len(s.OneOf) > 0 {
+		matches := 0
+		for _, alt := range s.OneOf {
+			var altErrs []string
+			d.validate(alt, v, path, &altErrs)
+			if len(altErrs) == 0 {
+				matches++
+			}
+		}
+		switch {
+		case matches == 0:
+			fail("must match one of the allowed schemas")
+		case matches > 1:
+			fail("must match exactly one of the allowed schemas, matches %d", matches)
+		}
+		return
+	}
+	switch s.Type {
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if s.MinLength != nil && len(str) < *s.MinLength {
+			fail("must be at least %d characters", *s.MinLength)
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			fail("must be one of %s", strings.Join(s.Enum, ", "))
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				fail("must be an RFC 3339 date-time")
+			}
+		}
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			fail("must be an integer")
+			return
+		}
+		if _, err := n.Int64(); err != nil {
+			fail("must be an integer")
+		}
+	case "number":
+		if _, ok := v.(json.Number); !ok {
+			fail("must be a number")
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail("must be a boolean")
+		}
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		for i, item := range arr {
+			d.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		for name, val := range obj {
+			ps, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					fail("unknown property %q", name)
+				}
+				continue
+			}
+			d.validate(ps, val, path+"."+name, errs)
+		}
+	}
+}
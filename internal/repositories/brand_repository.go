@@ -0,0 +1,279 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-backend/internal/events"
+	"go-backend/internal/models"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BrandRepository struct{ db *gorm.DB }
+
+func NewBrandRepository(db *gorm.DB) *BrandRepository { return &BrandRepository{db: db} }
+
+func (r *BrandRepository) List(ctx context.Context) ([]models.BrandWithAliases, error) {
+	var brands []models.Brand
+	if err := r.db.WithContext(ctx).Order("name").Find(&brands).Error; err != nil {
+		return nil, err
+	}
+	return r.withAliases(ctx, brands)
+}
+
+func (r *BrandRepository) Get(ctx context.Context, id int64) (*models.BrandWithAliases, error) {
+	var b models.Brand
+	if err := r.db.WithContext(ctx).First(&b, id).Error; err != nil {
+		return nil, err
+	}
+	list, err := r.withAliases(ctx, []models.Brand{b})
+	if err != nil {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+func (r *BrandRepository) Create(ctx context.Context, name string, aliases []string) (int64, error) {
+	b := models.Brand{Name: name, Key: models.BrandKey(name), CreatedAt: time.Now().UTC()}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkBrandKeys(tx, 0, b.Key, aliases); err != nil {
+			return err
+		}
+		if err := tx.Create(&b).Error; err != nil {
+			return err
+		}
+		return setAliases(tx, b.ID, aliases)
+	})
+	return b.ID, err
+}
+
+// Update renames a brand and replaces its aliases. Devices of the brand take
+// the new name; they are returned as written.
+func (r *BrandRepository) Update(ctx context.Context, id int64, name string, aliases []string) ([]models.Device, error) {
+	var changed []models.Device
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.Brand{}, id).Error; err != nil {
+			return err
+		}
+		key := models.BrandKey(name)
+		if err := checkBrandKeys(tx, id, key, aliases); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Brand{}).Where("id = ?", id).Updates(map[string]any{"name": name, "key": key}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("brand_id = ?", id).Delete(&models.BrandAlias{}).Error; err != nil {
+			return err
+		}
+		if err := setAliases(tx, id, aliases); err != nil {
+			return err
+		}
+		var err error
+		changed, err = rebrand(tx, models.Brand{ID: id, Name: name}, "brand_id = ? AND brand <> ?", id, name)
+		return err
+	})
+	return changed, err
+}
+
+func (r *BrandRepository) Delete(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.Brand{}, id).Error; err != nil {
+			return err
+		}
+		var n int64
+		if err := tx.Model(&models.Device{}).Where("brand_id = ?", id).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return models.ErrBrandInUse
+		}
+		if err := tx.Where("brand_id = ?", id).Delete(&models.BrandAlias{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Brand{}, id).Error
+	})
+}
+
+// Normalize points every device at the brand its free-text brand resolves
+// to, creating brands for unknown names, and renames the device to the
+// canonical spelling. Brands left without devices whose name has become an
+// alias of another brand are removed. Changed devices are recorded as
+// updated. It returns the devices changed and the number of brands removed.
+func (r *BrandRepository) Normalize(ctx context.Context) (devices []models.Device, removed int64, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var names []string
+		if err := tx.Model(&models.Device{}).Distinct().Pluck("brand", &names).Error; err != nil {
+			return err
+		}
+		for _, name := range names {
+			b, err := resolveBrand(tx, name)
+			if err != nil {
+				return err
+			}
+			changed, err := rebrand(tx, *b, "brand = ? AND (brand_id IS NULL OR brand_id <> ? OR brand <> ?)", name, b.ID, b.Name)
+			if err != nil {
+				return err
+			}
+			devices = append(devices, changed...)
+		}
+		res := tx.Where("NOT EXISTS (SELECT 1 FROM devices WHERE devices.brand_id = brands.id)").
+			Where("key IN (SELECT key FROM brand_aliases WHERE brand_aliases.brand_id <> brands.id)").
+			Delete(&models.Brand{})
+		removed = res.RowsAffected
+		return res.Error
+	})
+	return devices, removed, err
+}
+
+// rebrand points the devices matching query at b, records each as updated
+// and returns them as written.
+func rebrand(tx *gorm.DB, b models.Brand, query string, args ...any) ([]models.Device, error) {
+	var ids []int64
+	if err := tx.Model(&models.Device{}).Where(query, args...).Pluck("id", &ids).Error; err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	err := tx.Model(&models.Device{}).Where("id IN ?", ids).
+		Updates(map[string]any{"brand": b.Name, "brand_id": b.ID, "updated_at": models.NowFormattedTime()}).Error
+	if err != nil {
+		return nil, err
+	}
+	var changed []models.Device
+	if err := tx.Where("id IN ?", ids).Find(&changed).Error; err != nil {
+		return nil, err
+	}
+	for i := range changed {
+		if err := writeOutbox(tx, events.Updated, &changed[i]); err != nil {
+			return nil, err
+		}
+	}
+	return changed, nil
+}
+
+func (r *BrandRepository) withAliases(ctx context.Context, brands []models.Brand) ([]models.BrandWithAliases, error) {
+	ids := make([]int64, len(brands))
+	for i, b := range brands {
+		ids[i] = b.ID
+	}
+	var aliases []models.BrandAlias
+	if err := r.db.WithContext(ctx).Where("brand_id IN ?", ids).Order("alias").Find(&aliases).Error; err != nil {
+		return nil, err
+	}
+	var counts []struct {
+		BrandID int64
+		N       int64
+	}
+	err := r.db.WithContext(ctx).Model(&models.Device{}).Select("brand_id, COUNT(*) AS n").
+		Where("brand_id IN ?", ids).Group("brand_id").Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.BrandWithAliases, len(brands))
+	for i, b := range brands {
+		out[i] = models.BrandWithAliases{Brand: b, Aliases: []string{}}
+		for _, a := range aliases {
+			if a.BrandID == b.ID {
+				out[i].Aliases = append(out[i].Aliases, a.Alias)
+			}
+		}
+		for _, c := range counts {
+			if c.BrandID == b.ID {
+				out[i].Devices = c.N
+			}
+		}
+	}
+	return out, nil
+}
+
+// checkBrandKeys rejects a name or aliases that already identify a brand
+// other than id. An alias may take over the name of another brand, which is
+// how duplicates are folded into the canonical brand before Normalize.
+func checkBrandKeys(tx *gorm.DB, id int64, key string, aliases []string) error {
+	if key == "" {
+		return models.ErrInvalidBrand
+	}
+	var n int64
+	err := tx.Model(&models.Brand{}).Where("key = ? AND id <> ?", key, id).Count(&n).Error
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		err = tx.Model(&models.BrandAlias{}).Where("key = ? AND brand_id <> ?", key, id).Count(&n).Error
+	}
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return models.ErrBrandConflict
+	}
+	keys := make([]string, 0, len(aliases))
+	for _, a := range aliases {
+		k := models.BrandKey(a)
+		if k == "" {
+			return models.ErrInvalidBrand
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := tx.Model(&models.BrandAlias{}).Where("key IN ? AND brand_id <> ?", keys, id).Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return models.ErrBrandConflict
+	}
+	return nil
+}
+
+// setAliases stores aliases of a brand; aliases spelled like the brand
+// itself or like each other are kept once.
+func setAliases(tx *gorm.DB, id int64, aliases []string) error {
+	var b models.Brand
+	if err := tx.First(&b, id).Error; err != nil {
+		return err
+	}
+	seen := []string{b.Key}
+	for _, a := range aliases {
+		a = strings.TrimSpace(a)
+		k := models.BrandKey(a)
+		if slices.Contains(seen, k) {
+			continue
+		}
+		seen = append(seen, k)
+		if err := tx.Create(&models.BrandAlias{Key: k, Alias: a, BrandID: id}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findBrand looks name up among aliases first, so an alias can take over a
+// duplicate brand, and then among brand names.
+func findBrand(tx *gorm.DB, name string) (*models.Brand, error) {
+	key := models.BrandKey(name)
+	var b models.Brand
+	err := tx.Where("id = (SELECT brand_id FROM brand_aliases WHERE key = ?)", key).First(&b).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = tx.Where("key = ?", key).First(&b).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// resolveBrand finds the brand name refers to, creating it on first use.
+func resolveBrand(tx *gorm.DB, name string) (*models.Brand, error) {
+	b, err := findBrand(tx, name)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return b, err
+	}
+	b = &models.Brand{Name: strings.Join(strings.Fields(name), " "), Key: models.BrandKey(name), CreatedAt: time.Now().UTC()}
+	if err := tx.Create(b).Error; err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CategoryRepository struct{ db *gorm.DB }
+
+func NewCategoryRepository(db *gorm.DB) *CategoryRepository { return &CategoryRepository{db: db} }
+
+func (r *CategoryRepository) List(ctx context.Context) ([]models.CategorySchema, error) {
+	var list []models.CategorySchema
+	if err := r.db.WithContext(ctx).Order("category").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *CategoryRepository) Get(ctx context.Context, category string) (*models.CategorySchema, error) {
+	var s models.CategorySchema
+	if err := r.db.WithContext(ctx).Where("category = ?", category).First(&s).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Put registers or replaces the schema of a category.
+func (r *CategoryRepository) Put(ctx context.Context, s *models.CategorySchema) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(s).Error
+}
+
+func (r *CategoryRepository) Delete(ctx context.Context, category string) error {
+	res := r.db.WithContext(ctx).Where("category = ?", category).Delete(&models.CategorySchema{})
+	if res.Error == nil && res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return res.Error
+}
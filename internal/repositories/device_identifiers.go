@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// identifierColumns are the device columns that identify a physical device.
+var identifierColumns = []string{"serial_number", "asset_tag"}
+
+// GetByIdentifier reads the device whose serial_number or asset_tag column
+// holds value.
+func (r *DeviceRepository) GetByIdentifier(ctx context.Context, column, value string) (*models.Device, error) {
+	var d models.Device
+	if err := r.db.WithContext(ctx).Where(column+" = ?", value).First(&d).Error; err != nil {
+		return nil, deviceNotFound(err)
+	}
+	return &d, nil
+}
+
+// checkIdentifiers fails with a DuplicateError when a serial number or asset
+// tag among fields belongs to a device other than id.
+func checkIdentifiers(tx *gorm.DB, id int64, fields map[string]any) error {
+	for _, col := range identifierColumns {
+		var value string
+		switch v := fields[col].(type) {
+		case string:
+			value = v
+		case *string:
+			if v == nil {
+				continue
+			}
+			value = *v
+		default:
+			continue
+		}
+		var other models.Device
+		err := tx.Select("id").Where(col+" = ? AND id <> ?", value, id).First(&other).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		return &models.DuplicateError{Field: col, Value: value, DeviceID: other.ID}
+	}
+	return nil
+}
+
+// identifierConflict turns a unique-constraint violation into the
+// DuplicateError naming the device that holds the serial number or asset tag.
+// checkIdentifiers only checks ahead of the write, so a concurrent write can
+// take the value in between; the unique index then rejects ours. Other errors
+// are returned unchanged.
+func (r *DeviceRepository) identifierConflict(ctx context.Context, id int64, fields map[string]any, err error) error {
+	t, ok := r.db.Dialector.(gorm.ErrorTranslator)
+	if err == nil || !ok || !errors.Is(t.Translate(err), gorm.ErrDuplicatedKey) {
+		return err
+	}
+	var dup *models.DuplicateError
+	if errors.As(checkIdentifiers(r.db.WithContext(ctx), id, fields), &dup) {
+		return dup
+	}
+	return err
+}
@@ -2,55 +2,437 @@ package repositories
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-backend/internal/events"
 	"go-backend/internal/models"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
 	"gorm.io/gorm"
 )
 
-type DeviceRepository struct{ db *gorm.DB }
+var errWrongState = errors.New("device not in expected state")
+
+type DeviceRepository struct {
+	db           *gorm.DB
+	createBrands bool
+}
+
+type DeviceRepositoryOption func(*DeviceRepository)
 
-func NewDeviceRepository(db *gorm.DB) *DeviceRepository { return &DeviceRepository{db: db} }
+// WithBrandCreation sets whether a device written with an unknown brand
+// creates that brand, as it does by default, or fails with ErrUnknownBrand.
+func WithBrandCreation(create bool) DeviceRepositoryOption {
+	return func(r *DeviceRepository) { r.createBrands = create }
+}
+
+func NewDeviceRepository(db *gorm.DB, opts ...DeviceRepositoryOption) *DeviceRepository {
+	r := &DeviceRepository{db: db, createBrands: true}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
 
 func (r *DeviceRepository) Create(ctx context.Context, d *models.Device) (int64, error) {
 	if err := d.ValidateNew(); err != nil {
 		return 0, err
 	}
-	if err := r.db.WithContext(ctx).Create(d).Error; err != nil {
-		return 0, err
+	identifiers := map[string]any{"serial_number": d.SerialNumber, "asset_tag": d.AssetTag}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkAttributes(tx, d); err != nil {
+			return err
+		}
+		if err := checkIdentifiers(tx, 0, identifiers); err != nil {
+			return err
+		}
+		b, err := r.brand(tx, d.Brand)
+		if err != nil {
+			return err
+		}
+		d.Brand, d.BrandID = b.Name, &b.ID
+		if err := tx.Create(d).Error; err != nil {
+			return err
+		}
+		return writeOutbox(tx, events.Created, d)
+	})
+	if err != nil {
+		return 0, r.identifierConflict(ctx, 0, identifiers, err)
 	}
 	return d.ID, nil
 }
 
-func (r *DeviceRepository) Get(ctx context.Context, id int64) (*models.Device, error) {
+// Get reads a device; columns, when given, limit the columns read and leave
+// the other fields zero.
+func (r *DeviceRepository) Get(ctx context.Context, id int64, columns ...string) (*models.Device, error) {
 	var d models.Device
-	if err := r.db.WithContext(ctx).First(&d, id).Error; err != nil {
-		return nil, err
+	if err := selected(r.db.WithContext(ctx), columns).First(&d, id).Error; err != nil {
+		return nil, deviceNotFound(err)
 	}
 	return &d, nil
 }
 
-func (r *DeviceRepository) List(ctx context.Context, brand, state string) ([]models.Device, error) {
+func (r *DeviceRepository) List(ctx context.Context, f models.DeviceFilter, columns ...string) ([]models.Device, error) {
+	var list []models.Device
+	if err := selected(r.filtered(ctx, f), columns).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListPage returns up to limit devices with an id greater than afterID in id
+// order, so callers can page with the last id they have seen.
+func (r *DeviceRepository) ListPage(ctx context.Context, f models.DeviceFilter, afterID int64, limit int) ([]models.Device, error) {
 	var list []models.Device
+	if err := r.filtered(ctx, f).Where("id > ?", afterID).Order("id").Limit(limit).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// LastChange returns when any device was last created, changed or deleted,
+// or the zero time if that was never recorded.
+func (r *DeviceRepository) LastChange(ctx context.Context) (time.Time, error) {
+	var times []time.Time
+	err := r.db.WithContext(ctx).Model(&models.DeviceChange{}).Order("id DESC").Limit(1).Pluck("created_at", &times).Error
+	if err != nil || len(times) == 0 {
+		return time.Time{}, err
+	}
+	return times[0], nil
+}
+
+// History returns up to limit of the most recent changes of each of the
+// devices, newest first, in one query.
+func (r *DeviceRepository) History(ctx context.Context, ids []int64, limit int) (map[int64][]models.DeviceChange, error) {
+	ranked := r.db.Model(&models.DeviceChange{}).
+		Select("*, ROW_NUMBER() OVER (PARTITION BY device_id ORDER BY id DESC) AS position").
+		Where("device_id IN ?", ids)
+	var list []models.DeviceChange
+	err := r.db.WithContext(ctx).Table("(?) AS ranked", ranked).
+		Where("position <= ?", limit).Order("device_id, id DESC").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	byDevice := make(map[int64][]models.DeviceChange, len(ids))
+	for _, c := range list {
+		byDevice[c.DeviceID] = append(byDevice[c.DeviceID], c)
+	}
+	return byDevice, nil
+}
+
+// createdDay renders created_at, stored as DbTimeLayout, as YYYY-MM-DD so
+// SQLite's date functions can bucket it.
+const createdDay = `CASE WHEN created_at LIKE '__.__.____ %' ` +
+	`THEN substr(created_at, 7, 4) || '-' || substr(created_at, 4, 2) || '-' || substr(created_at, 1, 2) ` +
+	`ELSE substr(created_at, 1, 10) END`
+
+var periods = map[models.StatsInterval]string{
+	models.IntervalDay:   createdDay,
+	models.IntervalWeek:  "date(" + createdDay + ", 'weekday 0', '-6 days')",
+	models.IntervalMonth: "substr(" + createdDay + ", 1, 7)",
+}
+
+// Stats counts devices with a single GROUP BY over the requested dimensions.
+func (r *DeviceRepository) Stats(ctx context.Context, q models.StatsQuery) ([]models.DeviceCount, error) {
+	var dims []string
+	if q.ByBrand {
+		dims = append(dims, "brand")
+	}
+	if q.ByState {
+		dims = append(dims, "state")
+	}
+	cols := slices.Clone(dims)
+	if q.Interval != "" {
+		expr, ok := periods[q.Interval]
+		if !ok {
+			return nil, fmt.Errorf("unknown interval %q", q.Interval)
+		}
+		cols = append(cols, expr+" AS period")
+		dims = append(dims, "period")
+	}
+	tx := r.filtered(ctx, models.DeviceFilter{Brand: q.Brand, State: q.State})
+	if !q.From.IsZero() {
+		tx = tx.Where(createdDay+" >= ?", q.From.Format(time.DateOnly))
+	}
+	if !q.To.IsZero() {
+		tx = tx.Where(createdDay+" <= ?", q.To.Format(time.DateOnly))
+	}
+	tx = tx.Select(strings.Join(append(cols, "COUNT(*) AS count"), ", "))
+	if len(dims) > 0 {
+		tx = tx.Group(strings.Join(dims, ", ")).Order(strings.Join(dims, ", "))
+	}
+	var out []models.DeviceCount
+	if err := tx.Scan(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// attributeText renders the attribute at a JSON path the way it is written
+// in a query string, so attr.os_version=14 matches both 14 and "14".
+const attributeText = `CASE json_type(attributes, ?) WHEN 'true' THEN 'true' WHEN 'false' THEN 'false' ` +
+	`ELSE CAST(json_extract(attributes, ?) AS TEXT) END`
+
+func selected(q *gorm.DB, columns []string) *gorm.DB {
+	if len(columns) == 0 {
+		return q
+	}
+	return q.Select(columns)
+}
+
+func (r *DeviceRepository) filtered(ctx context.Context, f models.DeviceFilter) *gorm.DB {
 	q := r.db.WithContext(ctx).Model(&models.Device{})
-	if brand != "" {
-		q = q.Where("brand = ?", brand)
+	if f.Brand != "" {
+		q = q.Where("brand = ?", f.Brand)
+	}
+	if f.State != "" {
+		q = q.Where("state = ?", f.State)
+	}
+	if f.Category != "" {
+		q = q.Where("category = ?", f.Category)
+	}
+	for _, name := range slices.Sorted(maps.Keys(f.Attributes)) {
+		// AttributePath was checked by the caller.
+		path, _ := models.AttributePath(name)
+		q = q.Where(attributeText+" = ?", path, path, f.Attributes[name])
+	}
+	if len(f.Tags) > 0 {
+		tagged := r.db.Table("device_tags").Select("device_tags.device_id").
+			Joins("JOIN tags ON tags.id = device_tags.tag_id").Where("tags.name IN ?", f.Tags)
+		if f.AllTags {
+			tagged = tagged.Group("device_tags.device_id").Having("COUNT(*) = ?", len(f.Tags))
+		}
+		q = q.Where("id IN (?)", tagged)
+	}
+	return q
+}
+
+// Update replaces the fields of a device and returns the row it wrote.
+func (r *DeviceRepository) Update(ctx context.Context, id int64, d *models.Device) (*models.Device, error) {
+	fields := map[string]any{
+		"name": d.Name, "brand": d.Brand, "state": d.State, "category": d.Category, "attributes": d.Attributes,
+		"serial_number": d.SerialNumber, "asset_tag": d.AssetTag,
+	}
+	written, err := r.change(ctx, id, events.Updated, func(tx *gorm.DB) error {
+		if err := checkIdentifiers(tx, id, fields); err != nil {
+			return err
+		}
+		return tx.Model(&models.Device{}).Where("id = ?", id).Updates(fields).Error
+	})
+	return written, r.identifierConflict(ctx, id, fields, err)
+}
+
+// Patch updates fields of a device and returns the row it wrote.
+func (r *DeviceRepository) Patch(ctx context.Context, id int64, fields map[string]any) (*models.Device, error) {
+	written, err := r.change(ctx, id, events.Patched, func(tx *gorm.DB) error {
+		if err := checkIdentifiers(tx, id, fields); err != nil {
+			return err
+		}
+		return tx.Model(&models.Device{}).Where("id = ?", id).Updates(fields).Error
+	})
+	return written, r.identifierConflict(ctx, id, fields, err)
+}
+
+// PatchIfUnchanged applies fields only while the device still matches
+// before, and returns the row it wrote, or nil if the device had changed.
+func (r *DeviceRepository) PatchIfUnchanged(ctx context.Context, before *models.Device, fields map[string]any) (*models.Device, error) {
+	d, err := r.change(ctx, before.ID, events.Patched, func(tx *gorm.DB) error {
+		if err := checkIdentifiers(tx, before.ID, fields); err != nil {
+			return err
+		}
+		res := tx.Model(&models.Device{}).
+			Where("id = ? AND name = ? AND brand = ? AND state = ? AND category = ? AND attributes = ?",
+				before.ID, before.Name, before.Brand, before.State, before.Category, before.Attributes).
+			Where("serial_number IS ? AND asset_tag IS ?", before.SerialNumber, before.AssetTag).
+			Updates(fields)
+		if res.Error == nil && res.RowsAffected != 1 {
+			return errWrongState
+		}
+		return res.Error
+	})
+	if errors.Is(err, errWrongState) {
+		return nil, nil
 	}
-	if state != "" {
-		q = q.Where("state = ?", state)
+	return d, r.identifierConflict(ctx, before.ID, fields, err)
+}
+
+// TransitionState moves a device from one state to another with a
+// conditional update and returns the row it wrote, or nil if the device was
+// not in the expected state.
+func (r *DeviceRepository) TransitionState(ctx context.Context, id int64, from, to models.State) (*models.Device, error) {
+	d, err := r.change(ctx, id, events.Patched, func(tx *gorm.DB) error {
+		res := tx.Model(&models.Device{}).Where("id = ? AND state = ?", id, from).Update("state", to)
+		if res.Error == nil && res.RowsAffected != 1 {
+			return errWrongState
+		}
+		return res.Error
+	})
+	if errors.Is(err, errWrongState) {
+		return nil, nil
 	}
-	if err := q.Find(&list).Error; err != nil {
+	return d, err
+}
+
+// Delete removes a device with its tags, reservations and leases, and
+// returns the row it deleted.
+func (r *DeviceRepository) Delete(ctx context.Context, id int64) (*models.Device, error) {
+	var d models.Device
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&d, id).Error; err != nil {
+			return deviceNotFound(err)
+		}
+		if err := tx.Delete(&models.Device{}, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("device_id = ?", id).Delete(&models.DeviceTag{}).Error; err != nil {
+			return err
+		}
+		if err := pruneTags(tx); err != nil {
+			return err
+		}
+		if err := tx.Where("device_id = ?", id).Delete(&models.Reservation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("device_id = ?", id).Delete(&models.Lease{}).Error; err != nil {
+			return err
+		}
+		return writeOutbox(tx, events.Deleted, &d)
+	})
+	if err != nil {
 		return nil, err
 	}
-	return list, nil
+	return &d, nil
 }
 
-func (r *DeviceRepository) Update(ctx context.Context, id int64, d *models.Device) error {
-	return r.db.WithContext(ctx).Model(&models.Device{}).Where("id = ?", id).Updates(map[string]any{"name": d.Name, "brand": d.Brand, "state": d.State}).Error
+// change applies fn and records the resulting device in the outbox within
+// one transaction, adding a state_changed event when the state moved. A
+// device leaving in-use ends its lease. It returns the device as written, so
+// callers publish the row that was committed rather than reading it again.
+func (r *DeviceRepository) change(ctx context.Context, id int64, t events.Type, fn func(tx *gorm.DB) error) (*models.Device, error) {
+	var after models.Device
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var before models.Device
+		if err := tx.First(&before, id).Error; err != nil {
+			return deviceNotFound(err)
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if err := r.syncBrand(tx, &before); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Device{}).Where("id = ?", id).Update("updated_at", models.NowFormattedTime()).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&after, id).Error; err != nil {
+			return err
+		}
+		if after.Category != before.Category || !reflect.DeepEqual(after.Attributes, before.Attributes) {
+			if err := checkAttributes(tx, &after); err != nil {
+				return err
+			}
+		}
+		if err := writeOutbox(tx, t, &after); err != nil {
+			return err
+		}
+		if after.State == before.State {
+			return nil
+		}
+		if before.State == models.StateInUse {
+			if err := endLeases(tx, id, time.Now().UTC().Truncate(time.Second)); err != nil {
+				return err
+			}
+		}
+		return writeOutbox(tx, events.StateChanged, &after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &after, nil
 }
 
-func (r *DeviceRepository) Patch(ctx context.Context, id int64, fields map[string]any) error {
-	return r.db.WithContext(ctx).Model(&models.Device{}).Where("id = ?", id).Updates(fields).Error
+// syncBrand points a device whose brand changed, or that predates brands, at
+// the brand its name resolves to and stores the canonical name. The brand of
+// a device that predates brands is created even without brand creation, so
+// that such a device can still be changed.
+func (r *DeviceRepository) syncBrand(tx *gorm.DB, before *models.Device) error {
+	var cur models.Device
+	if err := tx.Select("brand", "brand_id").First(&cur, before.ID).Error; err != nil {
+		return err
+	}
+	if cur.BrandID != nil && cur.Brand == before.Brand {
+		return nil
+	}
+	resolve := r.brand
+	if cur.Brand == before.Brand {
+		resolve = resolveBrand
+	}
+	b, err := resolve(tx, cur.Brand)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.Device{}).Where("id = ?", before.ID).Updates(map[string]any{"brand": b.Name, "brand_id": b.ID}).Error
 }
 
-func (r *DeviceRepository) Delete(ctx context.Context, id int64) error {
-	return r.db.WithContext(ctx).Delete(&models.Device{}, id).Error
+// brand finds the brand name refers to for a device being written. An
+// unknown brand is created, or refused with ErrUnknownBrand when brand
+// creation is off.
+func (r *DeviceRepository) brand(tx *gorm.DB, name string) (*models.Brand, error) {
+	if r.createBrands {
+		return resolveBrand(tx, name)
+	}
+	b, err := findBrand(tx, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrUnknownBrand
+	}
+	return b, err
+}
+
+// CanonicalBrand returns the canonical spelling of a known brand name or
+// alias, and name itself otherwise.
+func (r *DeviceRepository) CanonicalBrand(ctx context.Context, name string) (string, error) {
+	b, err := findBrand(r.db.WithContext(ctx), name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return name, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return b.Name, nil
+}
+
+// checkAttributes validates the attributes of d against the schema of its
+// category, if one is registered.
+func checkAttributes(tx *gorm.DB, d *models.Device) error {
+	if d.Category == "" {
+		return nil
+	}
+	var s models.CategorySchema
+	err := tx.Where("category = ?", d.Category).First(&s).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.Validate(d.Attributes)
+}
+
+// writeOutbox records a change in the device history and queues it for
+// webhooks.
+func writeOutbox(tx *gorm.DB, t events.Type, d *models.Device) error {
+	payload, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if err := tx.Create(&models.DeviceChange{Type: string(t), DeviceID: d.ID, Payload: string(payload), CreatedAt: now}).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.OutboxEvent{EventType: string(t), DeviceID: d.ID, Payload: string(payload), CreatedAt: now}).Error
 }
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-backend/internal/events"
+	"go-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errUnchanged = errors.New("nothing to change")
+
+// Tags returns the tag names of a device in name order.
+func (r *DeviceRepository) Tags(ctx context.Context, id int64) ([]string, error) {
+	var names []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.Device{}, id).Error; err != nil {
+			return err
+		}
+		return tx.Table("tags").Joins("JOIN device_tags ON device_tags.tag_id = tags.id").
+			Where("device_tags.device_id = ?", id).Order("tags.name").Pluck("tags.name", &names).Error
+	})
+	return names, deviceNotFound(err)
+}
+
+// AddTag tags a device, creating the tag on first use, and returns the
+// device it changed; a device that already carries the tag is not changed
+// and nil is returned.
+func (r *DeviceRepository) AddTag(ctx context.Context, id int64, name string) (*models.Device, error) {
+	d, err := r.change(ctx, id, events.Patched, func(tx *gorm.DB) error {
+		tag := models.Tag{Name: name}
+		if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).Create(&tag).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("name = ?", name).First(&tag).Error; err != nil {
+			return err
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.DeviceTag{DeviceID: id, TagID: tag.ID})
+		if res.Error == nil && res.RowsAffected == 0 {
+			return errUnchanged
+		}
+		return res.Error
+	})
+	if errors.Is(err, errUnchanged) {
+		return nil, nil
+	}
+	return d, deviceNotFound(err)
+}
+
+// RemoveTag untags a device, dropping the tag once no device carries it, and
+// returns the device it changed, if any.
+func (r *DeviceRepository) RemoveTag(ctx context.Context, id int64, name string) (*models.Device, error) {
+	d, err := r.change(ctx, id, events.Patched, func(tx *gorm.DB) error {
+		res := tx.Where("device_id = ? AND tag_id IN (?)", id, tx.Model(&models.Tag{}).Select("id").Where("name = ?", name)).
+			Delete(&models.DeviceTag{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errUnchanged
+		}
+		return pruneTags(tx)
+	})
+	if errors.Is(err, errUnchanged) {
+		return nil, nil
+	}
+	return d, deviceNotFound(err)
+}
+
+// ListTags returns every tag with the number of devices carrying it, in name
+// order.
+func (r *DeviceRepository) ListTags(ctx context.Context) ([]models.TagCount, error) {
+	var out []models.TagCount
+	err := r.db.WithContext(ctx).Table("tags").
+		Select("tags.name AS name, COUNT(device_tags.device_id) AS devices").
+		Joins("LEFT JOIN device_tags ON device_tags.tag_id = tags.id").
+		Group("tags.id").Order("tags.name").Scan(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func pruneTags(tx *gorm.DB) error {
+	return tx.Where("NOT EXISTS (SELECT 1 FROM device_tags WHERE device_tags.tag_id = tags.id)").Delete(&models.Tag{}).Error
+}
+
+func deviceNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.ErrDeviceNotFound
+	}
+	return err
+}
@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-backend/internal/events"
+	"go-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LeaseRepository struct{ db *gorm.DB }
+
+func NewLeaseRepository(db *gorm.DB) *LeaseRepository {
+	return &LeaseRepository{db: db}
+}
+
+// Acquire checks an available device out, records l as its lease and
+// returns the device as written. A lease that would run into a booked
+// reservation of the device is refused with a ReservationConflictError.
+func (r *LeaseRepository) Acquire(ctx context.Context, l *models.Lease) (*models.Device, error) {
+	var d *models.Device
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		d, err = NewDeviceRepository(tx).TransitionState(ctx, l.DeviceID, models.StateAvailable, models.StateInUse)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			return models.ErrNotAvailable
+		}
+		if err := checkBooked(tx, l.DeviceID, l.CreatedAt, l.ExpiresAt); err != nil {
+			return err
+		}
+		return tx.Create(l).Error
+	})
+	if err != nil {
+		return nil, deviceNotFound(err)
+	}
+	return d, nil
+}
+
+func (r *LeaseRepository) Get(ctx context.Context, id int64) (*models.Lease, error) {
+	var l models.Lease
+	if err := r.db.WithContext(ctx).First(&l, id).Error; err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// Active returns the active lease of a device.
+func (r *LeaseRepository) Active(ctx context.Context, deviceID int64) (*models.Lease, error) {
+	var l models.Lease
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.Device{}, deviceID).Error; err != nil {
+			return deviceNotFound(err)
+		}
+		return tx.Where("device_id = ? AND status = ?", deviceID, models.LeaseActive).First(&l).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// Renew moves the expiry of an active lease that has not expired by now,
+// unless a booked reservation of the device starts before the new expiry.
+func (r *LeaseRepository) Renew(ctx context.Context, id int64, expiresAt, now time.Time) (*models.Lease, error) {
+	var l models.Lease
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&l, id).Error; err != nil {
+			return err
+		}
+		if err := lockDevice(tx, l.DeviceID); err != nil {
+			return err
+		}
+		if err := checkBooked(tx, l.DeviceID, now, expiresAt); err != nil {
+			return err
+		}
+		q := tx.Model(&models.Lease{}).
+			Where("id = ? AND status = ? AND expires_at > ?", id, models.LeaseActive, now).
+			Update("expires_at", expiresAt)
+		if q.Error != nil {
+			return q.Error
+		}
+		if q.RowsAffected == 0 {
+			return models.ErrLeaseNotActive
+		}
+		l.ExpiresAt = expiresAt
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// Release ends an active lease and returns its device to available; changed
+// is the device as written, or nil if it did not change.
+func (r *LeaseRepository) Release(ctx context.Context, id int64, now time.Time) (l *models.Lease, changed *models.Device, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		l = &models.Lease{}
+		if err := tx.First(l, id).Error; err != nil {
+			return err
+		}
+		if err := end(tx, l, models.LeaseReleased, now); err != nil {
+			return err
+		}
+		changed, err = NewDeviceRepository(tx).TransitionState(ctx, l.DeviceID, models.StateInUse, models.StateAvailable)
+		return err
+	})
+	if errors.Is(err, errWrongState) {
+		err = models.ErrLeaseNotActive
+	}
+	return l, changed, err
+}
+
+// Reap expires the active leases whose expiry is not after now, returns
+// their devices to available and records a lease_expired event for each.
+// It returns the number of leases it expired and the devices it changed.
+// Every lease is claimed with a conditional update, so several instances
+// may reap concurrently without expiring a lease twice.
+func (r *LeaseRepository) Reap(ctx context.Context, now time.Time) (int, []models.Device, error) {
+	var due []models.Lease
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at <= ?", models.LeaseActive, now).
+		Order("expires_at").Find(&due).Error
+	if err != nil {
+		return 0, nil, err
+	}
+	expired := 0
+	var changed []models.Device
+	for i := range due {
+		l := &due[i]
+		var moved *models.Device
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := end(tx, l, models.LeaseExpired, now); err != nil {
+				return err
+			}
+			var err error
+			moved, err = NewDeviceRepository(tx).TransitionState(ctx, l.DeviceID, models.StateInUse, models.StateAvailable)
+			if err != nil {
+				return err
+			}
+			var d models.Device
+			if err := tx.First(&d, l.DeviceID).Error; err != nil {
+				return err
+			}
+			return writeOutbox(tx, events.LeaseExpired, &d)
+		})
+		if errors.Is(err, errWrongState) {
+			continue
+		}
+		if err != nil {
+			return expired, changed, err
+		}
+		expired++
+		if moved != nil {
+			changed = append(changed, *moved)
+		}
+	}
+	return expired, changed, nil
+}
+
+// end moves an active lease to status if no one else ended or renewed it
+// first.
+func end(tx *gorm.DB, l *models.Lease, status models.LeaseStatus, now time.Time) error {
+	q := tx.Model(&models.Lease{}).
+		Where("id = ? AND status = ? AND expires_at = ?", l.ID, models.LeaseActive, l.ExpiresAt).
+		Updates(map[string]any{"status": status, "ended_at": now})
+	if q.Error != nil {
+		return q.Error
+	}
+	if q.RowsAffected == 0 {
+		return errWrongState
+	}
+	l.Status, l.EndedAt = status, &now
+	return nil
+}
+
+// endLeases releases the active lease of a device that left in-use some other
+// way, so a later checkout is not cut short by the old lease expiring.
+func endLeases(tx *gorm.DB, deviceID int64, now time.Time) error {
+	return tx.Model(&models.Lease{}).
+		Where("device_id = ? AND status = ?", deviceID, models.LeaseActive).
+		Updates(map[string]any{"status": models.LeaseReleased, "ended_at": now}).Error
+}
@@ -0,0 +1,266 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// booked are the reservation statuses that hold their window.
+var booked = []models.ReservationStatus{models.ReservationScheduled, models.ReservationActive}
+
+type ReservationRepository struct{ db *gorm.DB }
+
+func NewReservationRepository(db *gorm.DB) *ReservationRepository {
+	return &ReservationRepository{db: db}
+}
+
+// Create books the window of res unless another reservation of the device
+// overlaps it or an active lease of the device runs past its start. The
+// device row is written first, so concurrent bookings and leases of a device
+// are serialized and cannot both pass the checks.
+func (r *ReservationRepository) Create(ctx context.Context, res *models.Reservation) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockDevice(tx, res.DeviceID); err != nil {
+			return err
+		}
+		if err := checkBooked(tx, res.DeviceID, res.StartsAt, res.EndsAt); err != nil {
+			return err
+		}
+		if err := checkLeased(tx, res.DeviceID, res.StartsAt); err != nil {
+			return err
+		}
+		return tx.Create(res).Error
+	})
+}
+
+// lockDevice writes the device row so that concurrent bookings and leases of
+// the device are serialized.
+func lockDevice(tx *gorm.DB, deviceID int64) error {
+	lock := tx.Exec("UPDATE devices SET id = id WHERE id = ?", deviceID)
+	if lock.Error != nil {
+		return lock.Error
+	}
+	if lock.RowsAffected == 0 {
+		return models.ErrDeviceNotFound
+	}
+	return nil
+}
+
+// checkBooked returns a ReservationConflictError if a booked reservation of
+// the device overlaps [from, to).
+func checkBooked(tx *gorm.DB, deviceID int64, from, to time.Time) error {
+	var other models.Reservation
+	err := tx.Select("id").
+		Where("device_id = ? AND status IN ? AND starts_at < ? AND ends_at > ?", deviceID, booked, to, from).
+		First(&other).Error
+	if err == nil {
+		return &models.ReservationConflictError{ReservationID: other.ID}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+// checkLeased returns ErrNotAvailable if an active lease of the device
+// expires after from.
+func checkLeased(tx *gorm.DB, deviceID int64, from time.Time) error {
+	var n int64
+	err := tx.Model(&models.Lease{}).
+		Where("device_id = ? AND status = ? AND expires_at > ?", deviceID, models.LeaseActive, from).
+		Count(&n).Error
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return models.ErrNotAvailable
+	}
+	return nil
+}
+
+func (r *ReservationRepository) Get(ctx context.Context, id int64) (*models.Reservation, error) {
+	var res models.Reservation
+	if err := r.db.WithContext(ctx).First(&res, id).Error; err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListForDevice returns the reservations of a device in start order.
+func (r *ReservationRepository) ListForDevice(ctx context.Context, deviceID int64) ([]models.Reservation, error) {
+	var list []models.Reservation
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.Device{}, deviceID).Error; err != nil {
+			return err
+		}
+		return tx.Where("device_id = ?", deviceID).Order("starts_at, id").Find(&list).Error
+	})
+	return list, deviceNotFound(err)
+}
+
+// Available returns the devices matching f that are not inactive and have
+// no booked reservation overlapping [from, to). When the window has already
+// begun, the device must also be available now.
+func (r *ReservationRepository) Available(ctx context.Context, f models.DeviceFilter, from, to, now time.Time) ([]models.Device, error) {
+	reserved := r.db.Model(&models.Reservation{}).Select("device_id").
+		Where("status IN ? AND starts_at < ? AND ends_at > ?", booked, to, from)
+	q := NewDeviceRepository(r.db).filtered(ctx, f).
+		Where("state <> ?", models.StateInactive).
+		Where("id NOT IN (?)", reserved)
+	if !from.After(now) {
+		q = q.Where("state = ?", models.StateAvailable)
+	}
+	var list []models.Device
+	if err := q.Order("id").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Cancel cancels a scheduled reservation. An active one ends at now instead,
+// and the device it checked out is returned; changed is that device as
+// written, or nil if the device did not change.
+func (r *ReservationRepository) Cancel(ctx context.Context, id int64, now time.Time) (res *models.Reservation, changed *models.Device, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res = &models.Reservation{}
+		if err := tx.First(res, id).Error; err != nil {
+			return err
+		}
+		switch res.Status {
+		case models.ReservationScheduled:
+			return claim(tx, res, models.ReservationCancelled, map[string]any{"status": models.ReservationCancelled})
+		case models.ReservationActive:
+			if now.Before(res.StartsAt) {
+				now = res.StartsAt
+			}
+			changed, err = finish(ctx, tx, res, now)
+			return err
+		default:
+			return models.ErrReservationEnded
+		}
+	})
+	if errors.Is(err, errWrongState) {
+		err = models.ErrConcurrentModification
+	}
+	return res, changed, err
+}
+
+// Start activates the scheduled reservations whose window contains now and
+// checks their devices out, and returns the devices it changed. Each
+// reservation is claimed with a conditional update, so several instances may
+// run Start concurrently.
+func (r *ReservationRepository) Start(ctx context.Context, now time.Time) ([]models.Device, error) {
+	var due []models.Reservation
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND starts_at <= ? AND ends_at > ?", models.ReservationScheduled, now, now).
+		Order("starts_at").Find(&due).Error
+	if err != nil {
+		return nil, err
+	}
+	var started []models.Device
+	for i := range due {
+		d, err := r.Activate(ctx, &due[i])
+		if err != nil {
+			return started, err
+		}
+		if d != nil {
+			started = append(started, *d)
+		}
+	}
+	return started, nil
+}
+
+// Activate claims the scheduled reservation res and checks its device out,
+// returning the device as written, or nil if the device did not change. A
+// reservation that another instance has already claimed is left alone.
+func (r *ReservationRepository) Activate(ctx context.Context, res *models.Reservation) (*models.Device, error) {
+	var d *models.Device
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := claim(tx, res, models.ReservationActive, map[string]any{"status": models.ReservationActive}); err != nil {
+			return err
+		}
+		// A device that is in use or inactive stays so; the reservation
+		// is active but leaves the device alone when it ends.
+		var err error
+		d, err = NewDeviceRepository(tx).TransitionState(ctx, res.DeviceID, models.StateAvailable, models.StateInUse)
+		if err != nil || d == nil {
+			return err
+		}
+		res.CheckedOut = true
+		return tx.Model(res).Update("checked_out", true).Error
+	})
+	if errors.Is(err, errWrongState) {
+		return nil, nil
+	}
+	return d, err
+}
+
+// End completes the reservations whose window has passed, returning the
+// devices they checked out, and returns the devices it changed. Like Start
+// it is safe to run on several instances.
+func (r *ReservationRepository) End(ctx context.Context, now time.Time) ([]models.Device, error) {
+	var due []models.Reservation
+	err := r.db.WithContext(ctx).Where("status IN ? AND ends_at <= ?", booked, now).Order("ends_at").Find(&due).Error
+	if err != nil {
+		return nil, err
+	}
+	var ended []models.Device
+	for i := range due {
+		res := &due[i]
+		var changed *models.Device
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			changed, err = finish(ctx, tx, res, res.EndsAt)
+			return err
+		})
+		if errors.Is(err, errWrongState) {
+			continue
+		}
+		if err != nil {
+			return ended, err
+		}
+		if changed != nil {
+			ended = append(ended, *changed)
+		}
+	}
+	return ended, nil
+}
+
+// finish completes a scheduled or active reservation at end and returns the
+// device it checked out, if the reservation still holds it: a device that was
+// returned and then leased by someone else stays with the lease. The device is
+// returned as written, or nil if it did not change.
+func finish(ctx context.Context, tx *gorm.DB, res *models.Reservation, end time.Time) (*models.Device, error) {
+	if err := claim(tx, res, models.ReservationCompleted, map[string]any{"status": models.ReservationCompleted, "ends_at": end}); err != nil {
+		return nil, err
+	}
+	if !res.CheckedOut {
+		return nil, nil
+	}
+	var leased int64
+	err := tx.Model(&models.Lease{}).Where("device_id = ? AND status = ?", res.DeviceID, models.LeaseActive).Count(&leased).Error
+	if err != nil || leased > 0 {
+		return nil, err
+	}
+	return NewDeviceRepository(tx).TransitionState(ctx, res.DeviceID, models.StateInUse, models.StateAvailable)
+}
+
+// claim moves res to status with fields if no one else moved it first.
+func claim(tx *gorm.DB, res *models.Reservation, status models.ReservationStatus, fields map[string]any) error {
+	q := tx.Model(&models.Reservation{}).Where("id = ? AND status = ?", res.ID, res.Status).Updates(fields)
+	if q.Error != nil {
+		return q.Error
+	}
+	if q.RowsAffected == 0 {
+		return errWrongState
+	}
+	res.Status = status
+	if end, ok := fields["ends_at"].(time.Time); ok {
+		res.EndsAt = end
+	}
+	return nil
+}
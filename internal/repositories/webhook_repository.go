@@ -0,0 +1,207 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WebhookRepository struct{ db *gorm.DB }
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository { return &WebhookRepository{db: db} }
+
+// DueDelivery is a claimed delivery together with what is needed to send it.
+type DueDelivery struct {
+	Delivery     models.WebhookDelivery
+	Subscription models.WebhookSubscription
+	Event        models.OutboxEvent
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, s *models.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Create(s).Error
+}
+
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id int64) (*models.WebhookSubscription, error) {
+	var s models.WebhookSubscription
+	if err := r.db.WithContext(ctx).First(&s, id).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	var list []models.WebhookSubscription
+	if err := r.db.WithContext(ctx).Order("id").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, s *models.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Save(s).Error
+}
+
+// DeleteSubscription removes the subscription and its pending deliveries; the
+// delivery history of finished attempts is kept.
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ? AND status = ?", id, models.DeliveryPending).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.WebhookSubscription{}, id).Error
+	})
+}
+
+// FanOut turns unprocessed outbox events into one pending delivery per
+// matching active subscription. Events are claimed with a conditional update
+// so concurrent dispatchers never fan out the same event twice.
+func (r *WebhookRepository) FanOut(ctx context.Context, limit int) (int, error) {
+	var pending []models.OutboxEvent
+	if err := r.db.WithContext(ctx).Where("processed_at IS NULL").Order("id").Limit(limit).Find(&pending).Error; err != nil {
+		return 0, err
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+	var subs []models.WebhookSubscription
+	if err := r.db.WithContext(ctx).Where("active = ?", true).Find(&subs).Error; err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, ev := range pending {
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			now := time.Now().UTC()
+			res := tx.Model(&models.OutboxEvent{}).Where("id = ? AND processed_at IS NULL", ev.ID).Update("processed_at", now)
+			if res.Error != nil || res.RowsAffected == 0 {
+				return res.Error
+			}
+			for _, s := range subs {
+				if !s.Matches(ev.EventType) {
+					continue
+				}
+				d := models.WebhookDelivery{
+					SubscriptionID: s.ID,
+					OutboxEventID:  ev.ID,
+					EventType:      ev.EventType,
+					Status:         models.DeliveryPending,
+					NextAttemptAt:  now,
+				}
+				if err := tx.Create(&d).Error; err != nil {
+					return err
+				}
+			}
+			n++
+			return nil
+		})
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// ClaimDue claims up to limit pending deliveries whose next attempt is due,
+// pushing their next attempt out by lease so a crashed sender's deliveries are
+// retried once the lease expires.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]DueDelivery, error) {
+	var due []models.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+		Order("next_attempt_at").Limit(limit).Find(&due).Error
+	if err != nil {
+		return nil, err
+	}
+	var out []DueDelivery
+	for _, d := range due {
+		res := r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND attempts = ?", d.ID, models.DeliveryPending, d.Attempts).
+			Updates(map[string]any{"attempts": d.Attempts + 1, "next_attempt_at": now.Add(lease), "updated_at": now})
+		if res.Error != nil {
+			return out, res.Error
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		d.Attempts++
+		item := DueDelivery{Delivery: d}
+		err := r.db.WithContext(ctx).First(&item.Subscription, d.SubscriptionID).Error
+		if err == nil {
+			err = r.db.WithContext(ctx).First(&item.Event, d.OutboxEventID).Error
+		}
+		// The subscription or event went away after the claim; nothing is
+		// left to deliver, so the delivery is dead.
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := r.MarkFailed(ctx, d.ID, 0, "subscription or event no longer exists", now, true); err != nil {
+				return out, err
+			}
+			continue
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func (r *WebhookRepository) MarkSucceeded(ctx context.Context, id int64, statusCode int) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("id = ?", id).Updates(map[string]any{
+		"status": models.DeliverySucceeded, "last_status_code": statusCode, "last_error": "", "delivered_at": now, "updated_at": now,
+	}).Error
+}
+
+// MarkFailed schedules the next attempt, or moves the delivery to the dead
+// letter list when dead is true.
+func (r *WebhookRepository) MarkFailed(ctx context.Context, id int64, statusCode int, msg string, next time.Time, dead bool) error {
+	fields := map[string]any{"last_status_code": statusCode, "last_error": msg, "next_attempt_at": next, "updated_at": time.Now().UTC()}
+	if dead {
+		fields["status"] = models.DeliveryDead
+	}
+	return r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, status string, limit int) ([]models.WebhookDelivery, error) {
+	var list []models.WebhookDelivery
+	q := r.db.WithContext(ctx).Model(&models.WebhookDelivery{})
+	if subscriptionID != 0 {
+		q = q.Where("subscription_id = ?", subscriptionID)
+	}
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if err := q.Order("id DESC").Limit(limit).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Requeue moves a dead delivery back to pending with a fresh attempt budget.
+func (r *WebhookRepository) Requeue(ctx context.Context, id int64) (bool, error) {
+	now := time.Now().UTC()
+	res := r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("id = ? AND status = ?", id, models.DeliveryDead).
+		Updates(map[string]any{"status": models.DeliveryPending, "attempts": 0, "next_attempt_at": now, "updated_at": now})
+	return res.RowsAffected == 1, res.Error
+}
+
+// Prune deletes finished (succeeded or dead) deliveries last touched before
+// cutoff, then the outbox events processed before cutoff that no delivery
+// refers to any more. It returns the number of outbox events deleted.
+func (r *WebhookRepository) Prune(ctx context.Context, cutoff time.Time) (int64, error) {
+	var pruned int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("status IN ? AND updated_at < ?", []models.DeliveryStatus{models.DeliverySucceeded, models.DeliveryDead}, cutoff).
+			Delete(&models.WebhookDelivery{}).Error
+		if err != nil {
+			return err
+		}
+		referenced := tx.Model(&models.WebhookDelivery{}).Select("outbox_event_id")
+		res := tx.Where("processed_at < ? AND id NOT IN (?)", cutoff, referenced).Delete(&models.OutboxEvent{})
+		pruned = res.RowsAffected
+		return res.Error
+	})
+	return pruned, err
+}
@@ -0,0 +1,152 @@
+package routers
+
+import (
+	"time"
+
+	"go-backend/config"
+	"go-backend/internal/health"
+	"go-backend/internal/models"
+	"go-backend/internal/services"
+)
+
+type Option func(*options)
+
+type options struct {
+	health  *health.Status
+	auth    config.AuthConfig
+	cors    config.CORSConfig
+	devices *services.DeviceService
+
+	heartbeat time.Duration
+
+	graphQLMaxDepth      int
+	graphQLMaxComplexity int
+
+	validateRequests  bool
+	validateResponses bool
+
+	unversionedDeprecation time.Time
+	unversionedSunset      time.Time
+
+	timeFormat models.TimeFormat
+	httpCache  config.HTTPCacheConfig
+	brands     config.BrandsConfig
+	labels     config.LabelsConfig
+	leases     config.LeasesConfig
+	webhooks   config.WebhooksConfig
+}
+
+func WithHealth(h *health.Status) Option {
+	return func(o *options) { o.health = h }
+}
+
+// WithAuth sets the credentials the admin routes require.
+func WithAuth(cfg config.AuthConfig) Option {
+	return func(o *options) { o.auth = cfg }
+}
+
+func WithCORS(cfg config.CORSConfig) Option {
+	return func(o *options) { o.cors = cfg }
+}
+
+// WithDeviceService shares a device service, and with it the event broker,
+// with other transports such as the gRPC server.
+func WithDeviceService(s *services.DeviceService) Option {
+	return func(o *options) { o.devices = s }
+}
+
+// WithHeartbeat sets the keep-alive interval for event streams.
+func WithHeartbeat(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.heartbeat = d
+		}
+	}
+}
+
+// WithGraphQLLimits bounds the depth and estimated cost of GraphQL queries.
+func WithGraphQLLimits(maxDepth, maxComplexity int) Option {
+	return func(o *options) {
+		o.graphQLMaxDepth = maxDepth
+		o.graphQLMaxComplexity = maxComplexity
+	}
+}
+
+// WithSpecValidation checks requests and/or responses against the generated OpenAPI document.
+func WithSpecValidation(requests, responses bool) Option {
+	return func(o *options) {
+		o.validateRequests = requests
+		o.validateResponses = responses
+	}
+}
+
+// WithUnversionedDeprecation sets the Deprecation and Sunset dates announced
+// by the unprefixed aliases of the /v1 routes; a zero sunset is left out.
+func WithUnversionedDeprecation(deprecation, sunset time.Time) Option {
+	return func(o *options) {
+		o.unversionedDeprecation = deprecation
+		o.unversionedSunset = sunset
+	}
+}
+
+// WithTimeFormat sets the time format of responses that do not select one.
+func WithTimeFormat(tf models.TimeFormat) Option {
+	return func(o *options) {
+		if tf.Valid() {
+			o.timeFormat = tf
+		}
+	}
+}
+
+// WithHTTPCache sets the Cache-Control headers of device reads.
+func WithHTTPCache(cfg config.HTTPCacheConfig) Option {
+	return func(o *options) { o.httpCache = cfg }
+}
+
+// WithBrands sets whether devices written with an unknown brand create it.
+// It applies to the device service the router creates, not to one passed
+// with WithDeviceService.
+func WithBrands(cfg config.BrandsConfig) Option {
+	return func(o *options) { o.brands = cfg }
+}
+
+// WithLabels sets the device URL encoded in label QR codes and the size
+// limit of label sheets.
+func WithLabels(cfg config.LabelsConfig) Option {
+	return func(o *options) { o.labels = cfg }
+}
+
+// WithLeases sets the default and maximum TTL of device leases.
+func WithLeases(cfg config.LeasesConfig) Option {
+	return func(o *options) { o.leases = cfg }
+}
+
+// WithWebhooks sets the hosts webhook subscriptions may target.
+func WithWebhooks(cfg config.WebhooksConfig) Option {
+	return func(o *options) { o.webhooks = cfg }
+}
+
+func newOptions(opts []Option) *options {
+	def := config.Default()
+	o := &options{
+		auth:                 def.Auth,
+		cors:                 def.CORS,
+		heartbeat:            def.Events.HeartbeatInterval,
+		graphQLMaxDepth:      def.GraphQL.MaxDepth,
+		graphQLMaxComplexity: def.GraphQL.MaxComplexity,
+		timeFormat:           models.TimeFormat(def.API.TimeFormat),
+		httpCache:            def.HTTPCache,
+		brands:               def.Brands,
+		labels:               def.Labels,
+		leases:               def.Leases,
+		webhooks:             def.Webhooks,
+	}
+	o.unversionedDeprecation, o.unversionedSunset, _ = def.API.Unversioned()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.health == nil {
+		o.health = health.NewStatus()
+	}
+	return o
+}
@@ -1,43 +1,64 @@
 package routers
 
 import (
+	"go-backend/database"
 	"go-backend/internal/handlers"
+	"go-backend/internal/health"
+	"go-backend/internal/labels"
 	"go-backend/internal/middlewares"
 	"go-backend/internal/repositories"
 	"go-backend/internal/services"
-	"os"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func New(db *gorm.DB) *gin.Engine {
+func New(db *gorm.DB, opts ...Option) *gin.Engine {
+	o := newOptions(opts)
 	r := gin.Default()
-	r.Use(middlewares.CORS())
+	r.Use(middlewares.CORS(o.cors))
 	r.Use(middlewares.GlobalRecovery())
-	repo := repositories.NewDeviceRepository(db)
-	svc := services.NewDeviceService(repo)
-	h := handlers.NewDeviceHandler(svc)
-	r.GET("/healthz", func(c *gin.Context) { c.Status(200) })
-	r.GET("/openapi.yaml", func(c *gin.Context) {
-		paths := []string{"openapi.yaml", "docs/swagger/openapi.yaml"}
-		for _, p := range paths {
-			if _, err := os.Stat(p); err == nil {
-				c.File(p)
-				return
-			}
-		}
-		c.Status(404)
-	})
-	r.GET("/docs", handlers.Docs)
-	grp := r.Group("/devices")
-	{
-		grp.POST("", h.Create)
-		grp.GET("", h.List)
-		grp.GET("/:id", h.Get)
-		grp.PUT("/:id", h.Update)
-		grp.PATCH("/:id", h.Patch)
-		grp.DELETE("/:id", h.Delete)
+	spec := Spec()
+	if o.validateRequests || o.validateResponses {
+		r.Use(middlewares.SpecValidation(spec, o.validateRequests, o.validateResponses))
 	}
+	svc := o.devices
+	if svc == nil {
+		svc = services.NewDeviceService(repositories.NewDeviceRepository(db, repositories.WithBrandCreation(o.brands.AutoCreate)))
+	}
+	o.health.Register("database", health.Database(db))
+	o.health.Register("migrations", health.Migrations(db, database.Models()...))
+	hs := handlerSet{
+		devices:      svc,
+		auth:         middlewares.Auth(o.auth),
+		reservations: services.NewReservationService(repositories.NewReservationRepository(db), svc),
+		timeFormat:   o.timeFormat,
+		cache:        handlers.CachePolicy{Get: o.httpCache.GetDevice, List: o.httpCache.ListDevices},
+		events:       handlers.NewEventsHandler(svc.Events(), o.heartbeat, o.timeFormat),
+		ws:           handlers.NewWSHandler(svc, middlewares.OriginAllowed(o.cors), o.timeFormat),
+		webhooks:     handlers.NewWebhookHandler(services.NewWebhookService(repositories.NewWebhookRepository(db), o.webhooks)),
+		category:     handlers.NewCategoryHandler(services.NewCategoryService(repositories.NewCategoryRepository(db))),
+		labels:       handlers.NewLabelHandler(svc, labels.NewRenderer(o.labels.DeviceURL), o.labels.MaxBatch),
+		leases:       handlers.NewLeaseHandler(services.NewLeaseService(repositories.NewLeaseRepository(db), svc, o.leases)),
+		brands:       handlers.NewBrandHandler(services.NewBrandService(repositories.NewBrandRepository(db), svc)),
+		graphql:      handlers.NewGraphQLHandler(svc, o.graphQLMaxDepth, o.graphQLMaxComplexity),
+		health:       handlers.NewHealthHandler(o.health),
+		metrics:      handlers.NewMetricsHandler(svc),
+	}
+	for _, rt := range hs.rootRoutes() {
+		r.Handle(rt.Method, rt.Path, rt.handler)
+	}
+	for _, v := range apiVersions(o) {
+		v.register(r, hs.versionedRoutes(v.mapper))
+	}
+	dh, err := handlers.NewDocsHandler(spec)
+	if err != nil {
+		panic(err)
+	}
+	r.GET("/openapi.yaml", dh.SpecYAML)
+	r.GET("/openapi.json", dh.SpecJSON)
+	r.GET("/docs", dh.SwaggerUI)
+	r.GET("/docs/redoc", dh.ReDoc)
+	r.GET("/docs/assets/*filepath", dh.Assets)
 	return r
 }
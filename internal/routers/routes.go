@@ -0,0 +1,599 @@
+package routers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go-backend/internal/dto"
+	"go-backend/internal/handlers"
+	"go-backend/internal/health"
+	"go-backend/internal/models"
+	"go-backend/internal/openapi"
+	"go-backend/internal/services"
+	apperror "go-backend/pkg/error"
+)
+
+var info = openapi.Info{
+	Title:       "Devices API",
+	Version:     "1.0.0",
+	Description: "REST API for managing device resources",
+}
+
+type route struct {
+	openapi.Operation
+	handler gin.HandlerFunc
+}
+
+type handlerSet struct {
+	devices      *services.DeviceService
+	auth         gin.HandlerFunc
+	reservations *services.ReservationService
+	events       *handlers.EventsHandler
+	ws           *handlers.WSHandler
+	webhooks     *handlers.WebhookHandler
+	category     *handlers.CategoryHandler
+	brands       *handlers.BrandHandler
+	labels       *handlers.LabelHandler
+	leases       *handlers.LeaseHandler
+	graphql      *handlers.GraphQLHandler
+	health       *handlers.HealthHandler
+	metrics      *handlers.MetricsHandler
+
+	timeFormat models.TimeFormat
+	cache      handlers.CachePolicy
+}
+
+var (
+	validationError = openapi.Response{Status: http.StatusBadRequest, Description: "Validation error", Body: apperror.ErrorPayload{}}
+	internalError   = openapi.Response{Status: http.StatusInternalServerError, Description: "Internal error", Body: apperror.ErrorPayload{}}
+	unprocessable   = openapi.Response{Status: http.StatusUnprocessableEntity, Description: "Business rule violation", Body: apperror.ErrorPayload{}}
+	noContent       = openapi.Response{Status: http.StatusNoContent}
+	notModified     = openapi.Response{Status: http.StatusNotModified, Description: "The client's copy is current"}
+	notFound        = openapi.Response{Status: http.StatusNotFound, Description: "Not found", Body: apperror.ErrorPayload{}}
+	duplicateDevice = openapi.Response{Status: http.StatusConflict, Description: "Serial number or asset tag belongs to another device; details carry its existing_id", Body: apperror.ErrorPayload{}}
+	brandConflict   = openapi.Response{Status: http.StatusConflict, Description: "Name or alias belongs to another brand", Body: apperror.ErrorPayload{}}
+	invalidBrand    = openapi.Response{Status: http.StatusUnprocessableEntity, Description: "Empty name or alias", Body: apperror.ErrorPayload{}}
+	invalidLease    = openapi.Response{Status: http.StatusUnprocessableEntity, Description: "Empty holder or ttl_seconds above leases.max_ttl", Body: apperror.ErrorPayload{}}
+	unauthorized    = openapi.Response{Status: http.StatusUnauthorized, Description: "Missing or invalid credentials", Body: apperror.ErrorPayload{}}
+	leaseNotActive  = openapi.Response{Status: http.StatusConflict, Description: "The lease has been released or has expired", Body: apperror.ErrorPayload{}}
+)
+
+// routes is the single source of truth for the API surface: routers.New
+// registers the root routes and, under every API version, the versioned
+// routes, and Spec documents them. Handlers may be nil when only the
+// operations are needed.
+func (hs handlerSet) routes(versions []apiVersion) []route {
+	routes := hs.rootRoutes()
+	for _, v := range versions {
+		routes = append(routes, v.mount(hs.versionedRoutes(v.mapper))...)
+	}
+	return routes
+}
+
+// admin requires the configured credentials before running h.
+func (hs handlerSet) admin(h gin.HandlerFunc) gin.HandlerFunc {
+	if h == nil || hs.auth == nil {
+		return h
+	}
+	return func(c *gin.Context) {
+		if hs.auth(c); !c.IsAborted() {
+			h(c)
+		}
+	}
+}
+
+// rootRoutes are not versioned.
+func (hs handlerSet) rootRoutes() []route {
+	return []route{
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/healthz", ID: "healthz", Tags: []string{"health"},
+			Summary:   "Legacy health check",
+			Responses: []openapi.Response{{Status: http.StatusOK}, {Status: http.StatusServiceUnavailable, Description: "Shutting down"}},
+		}, hs.health.Healthz},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/livez", ID: "livez", Tags: []string{"health"},
+			Summary:   "Liveness probe",
+			Responses: []openapi.Response{{Status: http.StatusOK, Body: map[string]string{}}},
+		}, hs.health.Live},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/readyz", ID: "readyz", Tags: []string{"health"},
+			Summary: "Readiness probe with dependency checks",
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: health.Report{}},
+				{Status: http.StatusServiceUnavailable, Description: "A check failed or shutdown has started", Body: health.Report{}},
+			},
+		}, hs.health.Ready},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/metrics/cache", ID: "deviceCacheStats", Tags: []string{"health"},
+			Summary:     "Device cache counters",
+			Description: "Hit, miss, eviction and expiration counts since start and the current number of cached devices.",
+			Responses:   []openapi.Response{{Status: http.StatusOK, Body: dto.CacheStatsResponse{}}},
+		}, hs.metrics.DeviceCache},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/graphql", ID: "graphql", Tags: []string{"graphql"},
+			Summary: "GraphQL endpoint for device queries and mutations",
+			Description: "Executes a GraphQL operation against the device schema. GraphQL errors are returned with status 200 " +
+				"and carry the REST error code in extensions.code; queries that are too deep (query_too_deep) or too " +
+				"expensive (query_too_complex) are rejected before execution.",
+			Body: dto.GraphQLRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.GraphQLResponse{}},
+				validationError,
+			},
+		}, hs.graphql.Serve},
+	}
+}
+
+// versionedRoutes are mounted once per API version; m renders the device
+// bodies of that version.
+func (hs handlerSet) versionedRoutes(m dto.DeviceMapper) []route {
+	devices := handlers.NewDeviceHandler(hs.devices, m, hs.timeFormat, hs.cache)
+	reservations := handlers.NewReservationHandler(hs.reservations, m, hs.timeFormat)
+	return []route{
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/devices", ID: "createDevice", Tags: []string{"devices"},
+			Summary: "Create device",
+			Params:  dto.TimeFormatParams{},
+			Body:    dto.CreateDeviceRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusCreated, Body: m.Device(&models.Device{}, hs.timeFormat)},
+				validationError, duplicateDevice, unprocessable, internalError,
+			},
+		}, devices.Create},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/devices", ID: "listDevices", Tags: []string{"devices"},
+			Summary: "List devices",
+			Description: "attr.<name>=<value> filters on attributes, e.g. attr.os_version=14 or attr.display.size=27; " +
+				"numbers and booleans match their text form. " +
+				"tag=lab,team-a returns devices carrying any of the tags, or all of them with tag_match=all. " +
+				"fields=id,name,state returns only the listed fields of each device. Responses carry an ETag and " +
+				"Last-Modified (the last change to any device) and answer If-None-Match / If-Modified-Since with 304.",
+			Params: dto.ListDevicesQuery{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: openapi.Partial(m.Devices(nil, hs.timeFormat))},
+				notModified, validationError, internalError,
+			},
+		}, devices.List},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/devices/stats", ID: "deviceStats", Tags: []string{"devices"},
+			Summary: "Count devices per brand, state and creation period",
+			Description: "group_by lists brand and/or state, interval buckets created_at by day, week (named by its Monday) " +
+				"or month. Without either, a single bucket counts every matching device. brand, state, from and to " +
+				"(inclusive creation dates) filter the devices counted.",
+			Params: dto.DeviceStatsQuery{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.DeviceStatsResponse{}},
+				validationError, internalError,
+			},
+		}, devices.Stats},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/devices/events", ID: "streamDeviceEvents", Tags: []string{"devices"},
+			Summary:     "Stream device changes",
+			Description: "Server-Sent Events stream of created/updated/patched/deleted events. Resume with Last-Event-ID; a reset event means the position is no longer buffered.",
+			Params:      dto.DeviceEventsQuery{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.DeviceEvent{}, ContentType: openapi.EventStreamContentType},
+				validationError,
+			},
+		}, hs.events.Stream},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/devices/ws", ID: "deviceWebSocket", Tags: []string{"devices"},
+			Summary: "WebSocket subscription API",
+			Description: "Upgrades to a WebSocket carrying JSON messages. Clients send subscribe/unsubscribe (device_ids) and " +
+				"command (checkout/checkin on device_id) messages; the server answers with ack or error and pushes event messages " +
+				"for subscribed devices. Clients that fall behind are disconnected with close code 1008.",
+			Params: dto.TimeFormatParams{},
+			Responses: []openapi.Response{
+				{Status: http.StatusSwitchingProtocols, Description: "Switching to the WebSocket protocol"},
+				{Status: http.StatusBadRequest, Description: "Not a WebSocket handshake"},
+				{Status: http.StatusForbidden, Description: "Origin not allowed"},
+			},
+		}, hs.ws.Serve},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/devices/available", ID: "listAvailableDevices", Tags: []string{"reservations"},
+			Summary: "List devices that can be booked for a window",
+			Description: "Devices that are not inactive and have no scheduled or active reservation overlapping " +
+				"[from, to). When from is not in the future the device must also be available now.",
+			Params: dto.AvailableDevicesQuery{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: m.Devices(nil, hs.timeFormat)},
+				validationError, internalError,
+			},
+		}, reservations.Available},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/devices/labels", ID: "printDeviceLabels", Tags: []string{"labels"},
+			Summary: "Print the labels of matching devices as a PDF",
+			Description: "A4 sheets of 3 x 8 labels (70 x 37 mm), in device ID order, for the devices matching the " +
+				"filters of listDevices, including attr.<name>. Returns 422 too_many_labels when more devices match " +
+				"than labels.max_batch allows.",
+			Params: dto.DeviceLabelsQuery{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: openapi.Binary{}, ContentType: handlers.PDFContentType},
+				validationError,
+				{Status: http.StatusUnprocessableEntity, Description: "Too many devices match", Body: apperror.ErrorPayload{}},
+				internalError,
+			},
+		}, hs.labels.Sheet},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/devices/by-serial/:serial", ID: "getDeviceBySerial", Tags: []string{"devices"},
+			Summary: "Look a device up by serial number",
+			Params:  dto.DeviceSerialParams{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: m.Device(&models.Device{}, hs.timeFormat)},
+				validationError, notFound, internalError,
+			},
+		}, devices.BySerial},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/devices/by-asset-tag/:asset_tag", ID: "getDeviceByAssetTag", Tags: []string{"devices"},
+			Summary: "Look a device up by asset tag",
+			Params:  dto.DeviceAssetTagParams{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: m.Device(&models.Device{}, hs.timeFormat)},
+				validationError, notFound, internalError,
+			},
+		}, devices.ByAssetTag},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/devices/:id", ID: "getDevice", Tags: []string{"devices"},
+			Summary: "Get device",
+			Description: "fields=id,name,state returns only the listed fields. Responses carry an ETag and Last-Modified " +
+				"and answer If-None-Match / If-Modified-Since with 304.",
+			Params: dto.GetDeviceParams{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: openapi.Partial(m.Device(&models.Device{}, hs.timeFormat))},
+				notModified, validationError, notFound, internalError,
+			},
+		}, devices.Get},
+		{openapi.Operation{
+			Method: http.MethodPut, Path: "/devices/:id", ID: "updateDevice", Tags: []string{"devices"},
+			Summary:     "Update device",
+			Description: "Fully update device; created_at must remain unchanged and name/brand are immutable while in-use",
+			Params:      dto.DeviceIDParams{},
+			Body:        dto.UpdateDeviceRequest{},
+			Responses:   []openapi.Response{noContent, validationError, notFound, duplicateDevice, unprocessable, internalError},
+		}, devices.Update},
+		{openapi.Operation{
+			Method: http.MethodPatch, Path: "/devices/:id", ID: "patchDevice", Tags: []string{"devices"},
+			Summary: "Patch device",
+			Description: "Partially update device; cannot update created_at; name/brand immutable if in-use. " +
+				"Accepts a partial JSON object, a JSON Merge Patch (application/merge-patch+json) or a JSON Patch " +
+				"(application/json-patch+json) applied to the device representation, rendered in the selected time format. A failed test operation returns 409 " +
+				"patch_test_failed, a device changed by another writer while patching returns 409 concurrent_modification, and a " +
+				"serial number or asset tag of another device returns 409 duplicate_device.",
+			Params: dto.DeviceIDParams{},
+			Body:   dto.PatchDeviceRequest{},
+			Bodies: map[string]any{
+				openapi.MergePatchContentType: dto.DeviceMergePatch{},
+				openapi.JSONPatchContentType:  []dto.JSONPatchOperation{},
+			},
+			Responses: []openapi.Response{
+				noContent, validationError, notFound,
+				{Status: http.StatusConflict, Description: "Test operation failed, concurrent modification or duplicate identifier", Body: apperror.ErrorPayload{}},
+				{Status: http.StatusUnsupportedMediaType, Description: "Unsupported patch format", Body: apperror.ErrorPayload{}},
+				unprocessable, internalError,
+			},
+		}, devices.Patch},
+		{openapi.Operation{
+			Method: http.MethodDelete, Path: "/devices/:id", ID: "deleteDevice", Tags: []string{"devices"},
+			Summary: "Delete device",
+			Params:  dto.DeviceIDParams{},
+			Responses: []openapi.Response{
+				noContent, validationError, notFound,
+				{Status: http.StatusConflict, Description: "In-use devices cannot be deleted", Body: apperror.ErrorPayload{}},
+				internalError,
+			},
+		}, devices.Delete},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/devices/:id/label", ID: "getDeviceLabel", Tags: []string{"labels"},
+			Summary: "Render the label of a device",
+			Description: "A QR code linking to the device (labels.device_url) next to its name, brand and ID, " +
+				"as a PNG or, with format=svg, an SVG.",
+			Params: dto.DeviceLabelParams{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: openapi.Binary{}, ContentType: handlers.PNGContentType},
+				{Status: http.StatusOK, Body: openapi.Binary{}, ContentType: handlers.SVGContentType},
+				validationError, notFound, internalError,
+			},
+		}, hs.labels.Device},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/devices/:id/lease", ID: "acquireLease", Tags: []string{"leases"},
+			Summary: "Check a device out under a lease",
+			Description: "Moves an available device to in-use until the lease is released or expires; ttl_seconds " +
+				"defaults to leases.default_ttl and may not exceed leases.max_ttl. An expired lease returns the device " +
+				"to available and records a lease_expired event. The lease also ends when the device leaves in-use " +
+				"some other way. Returns 409 reservation_conflict, with the reservation_id in details, when a booked " +
+				"reservation of the device starts before the lease would expire.",
+			Params: dto.DeviceIDParams{},
+			Body:   dto.AcquireLeaseRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusCreated, Body: dto.LeaseResponse{}},
+				validationError, notFound,
+				{Status: http.StatusConflict, Description: "The device is not available or is reserved before the lease would expire", Body: apperror.ErrorPayload{}},
+				invalidLease, internalError,
+			},
+		}, hs.leases.Acquire},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/devices/:id/lease", ID: "getDeviceLease", Tags: []string{"leases"},
+			Summary: "Get the active lease of a device",
+			Params:  dto.DeviceIDParams{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.LeaseResponse{}},
+				validationError,
+				{Status: http.StatusNotFound, Description: "Unknown device or no active lease", Body: apperror.ErrorPayload{}},
+				internalError,
+			},
+		}, hs.leases.Active},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/devices/:id/reservations", ID: "createReservation", Tags: []string{"reservations"},
+			Summary: "Book a device for a time window",
+			Description: "The device moves to in-use when the reservation starts and back to available when it ends, " +
+				"if it was available at the start. A reservation whose window has begun starts right away. Returns 409 " +
+				"reservation_conflict, with the overlapping reservation_id in details, when the window is taken, and " +
+				"409 device_not_available when an active lease of the device expires after the window starts.",
+			Params: dto.DeviceIDParams{},
+			Body:   dto.CreateReservationRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusCreated, Body: dto.ReservationResponse{}},
+				validationError, notFound,
+				{Status: http.StatusConflict, Description: "The window overlaps another reservation or an active lease", Body: apperror.ErrorPayload{}},
+				{Status: http.StatusUnprocessableEntity, Description: "Empty holder or window not ending in the future", Body: apperror.ErrorPayload{}},
+				internalError,
+			},
+		}, reservations.Create},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/devices/:id/reservations", ID: "listDeviceReservations", Tags: []string{"reservations"},
+			Summary: "List the reservations of a device in start order",
+			Params:  dto.DeviceIDParams{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: []dto.ReservationResponse{}},
+				validationError, notFound, internalError,
+			},
+		}, reservations.ListForDevice},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/devices/:id/tags", ID: "listDeviceTags", Tags: []string{"tags"},
+			Summary: "List the tags of a device",
+			Params:  dto.DeviceIDParams{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.DeviceTagsResponse{}},
+				validationError, notFound, internalError,
+			},
+		}, devices.Tags},
+		{openapi.Operation{
+			Method: http.MethodPut, Path: "/devices/:id/tags/:tag", ID: "addDeviceTag", Tags: []string{"tags"},
+			Summary: "Tag a device",
+			Description: "Tag names are lower-cased with spaces turned into dashes, so \"Test Lab\" and test-lab are the same " +
+				"tag. Adding a tag the device already carries does nothing.",
+			Params:    dto.DeviceTagParams{},
+			Responses: []openapi.Response{noContent, validationError, notFound, internalError},
+		}, devices.AddTag},
+		{openapi.Operation{
+			Method: http.MethodDelete, Path: "/devices/:id/tags/:tag", ID: "removeDeviceTag", Tags: []string{"tags"},
+			Summary:     "Untag a device",
+			Description: "A tag no device carries any more is deleted. Removing a tag the device does not carry does nothing.",
+			Params:      dto.DeviceTagParams{},
+			Responses:   []openapi.Response{noContent, validationError, notFound, internalError},
+		}, devices.RemoveTag},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/tags", ID: "listTags", Tags: []string{"tags"},
+			Summary:   "List tags with the number of devices carrying each",
+			Responses: []openapi.Response{{Status: http.StatusOK, Body: []dto.TagResponse{}}, internalError},
+		}, devices.ListTags},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/leases/:id", ID: "getLease", Tags: []string{"leases"},
+			Summary: "Get lease",
+			Params:  dto.LeaseIDParams{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.LeaseResponse{}},
+				validationError, notFound, internalError,
+			},
+		}, hs.leases.Get},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/leases/:id/renew", ID: "renewLease", Tags: []string{"leases"},
+			Summary: "Renew a lease",
+			Description: "The lease expires ttl_seconds, or leases.default_ttl, from now. Expired leases cannot be renewed, " +
+				"and a lease cannot be renewed into a booked reservation of its device (409 reservation_conflict).",
+			Params: dto.LeaseIDParams{},
+			Body:   dto.RenewLeaseRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.LeaseResponse{}},
+				validationError, notFound,
+				{Status: http.StatusConflict, Description: "The lease has been released or has expired, or the device is reserved before the new expiry", Body: apperror.ErrorPayload{}},
+				invalidLease, internalError,
+			},
+		}, hs.leases.Renew},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/leases/:id/release", ID: "releaseLease", Tags: []string{"leases"},
+			Summary:     "Release a lease",
+			Description: "Returns the device to available.",
+			Params:      dto.LeaseIDParams{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.LeaseResponse{}},
+				validationError, notFound, leaseNotActive, internalError,
+			},
+		}, hs.leases.Release},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/reservations/:id", ID: "getReservation", Tags: []string{"reservations"},
+			Summary: "Get reservation",
+			Params:  dto.ReservationIDParams{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.ReservationResponse{}},
+				validationError, notFound, internalError,
+			},
+		}, reservations.Get},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/reservations/:id/cancel", ID: "cancelReservation", Tags: []string{"reservations"},
+			Summary:     "Cancel a scheduled reservation or end an active one",
+			Description: "Ending an active reservation returns the device it checked out to available.",
+			Params:      dto.ReservationIDParams{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.ReservationResponse{}},
+				validationError, notFound,
+				{Status: http.StatusConflict, Description: "The reservation has already ended", Body: apperror.ErrorPayload{}},
+				internalError,
+			},
+		}, reservations.Cancel},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/brands", ID: "createBrand", Tags: []string{"brands"},
+			Summary: "Create a brand",
+			Description: "Devices created or updated with the brand's name or one of its aliases, in any case, are " +
+				"stored with the canonical name and brand_id. With brands.auto_create off, devices may only use " +
+				"registered brands.",
+			Body: dto.BrandRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusCreated, Body: dto.BrandResponse{}},
+				validationError, unauthorized, brandConflict, invalidBrand, internalError,
+			},
+		}, hs.admin(hs.brands.Create)},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/brands", ID: "listBrands", Tags: []string{"brands"},
+			Summary:   "List brands with their aliases and device counts",
+			Responses: []openapi.Response{{Status: http.StatusOK, Body: []dto.BrandResponse{}}, internalError},
+		}, hs.brands.List},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/brands/:id", ID: "getBrand", Tags: []string{"brands"},
+			Summary: "Get brand",
+			Params:  dto.BrandIDParams{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.BrandResponse{}},
+				validationError, notFound, internalError,
+			},
+		}, hs.brands.Get},
+		{openapi.Operation{
+			Method: http.MethodPut, Path: "/brands/:id", ID: "updateBrand", Tags: []string{"brands"},
+			Summary:     "Rename a brand and replace its aliases",
+			Description: "A new name is written to every device of the brand.",
+			Params:      dto.BrandIDParams{},
+			Body:        dto.BrandRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.BrandResponse{}},
+				validationError, unauthorized, notFound, brandConflict, invalidBrand, internalError,
+			},
+		}, hs.admin(hs.brands.Update)},
+		{openapi.Operation{
+			Method: http.MethodDelete, Path: "/brands/:id", ID: "deleteBrand", Tags: []string{"brands"},
+			Summary: "Delete a brand no device references",
+			Params:  dto.BrandIDParams{},
+			Responses: []openapi.Response{
+				noContent, validationError, unauthorized, notFound,
+				{Status: http.StatusConflict, Description: "Devices still reference the brand", Body: apperror.ErrorPayload{}},
+				internalError,
+			},
+		}, hs.admin(hs.brands.Delete)},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/admin/categories", ID: "listCategorySchemas", Tags: []string{"admin"},
+			Summary:   "List the attribute schemas of device categories",
+			Responses: []openapi.Response{{Status: http.StatusOK, Body: []dto.CategorySchemaResponse{}}, unauthorized, internalError},
+		}, hs.admin(hs.category.List)},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/admin/categories/:category/schema", ID: "getCategorySchema", Tags: []string{"admin"},
+			Summary: "Get the attribute schema of a device category",
+			Params:  dto.CategoryParams{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.CategorySchemaResponse{}},
+				unauthorized, notFound, internalError,
+			},
+		}, hs.admin(hs.category.Get)},
+		{openapi.Operation{
+			Method: http.MethodPut, Path: "/admin/categories/:category/schema", ID: "putCategorySchema", Tags: []string{"admin"},
+			Summary: "Register the attribute schema of a device category",
+			Description: "The body is a JSON Schema (draft 2020-12 unless $schema says otherwise) that the attributes of " +
+				"devices in the category must satisfy. External $ref targets are not resolved. Devices already stored " +
+				"are checked the next time their category or attributes change.",
+			Params: dto.CategoryParams{},
+			Body:   map[string]any{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.CategorySchemaResponse{}},
+				validationError, unauthorized,
+				{Status: http.StatusUnprocessableEntity, Description: "Not a valid JSON Schema", Body: apperror.ErrorPayload{}},
+				internalError,
+			},
+		}, hs.admin(hs.category.Put)},
+		{openapi.Operation{
+			Method: http.MethodDelete, Path: "/admin/categories/:category/schema", ID: "deleteCategorySchema", Tags: []string{"admin"},
+			Summary:   "Stop validating the attributes of a device category",
+			Params:    dto.CategoryParams{},
+			Responses: []openapi.Response{noContent, unauthorized, notFound, internalError},
+		}, hs.admin(hs.category.Delete)},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/webhooks", ID: "createWebhook", Tags: []string{"webhooks"},
+			Summary: "Subscribe a URL to device events",
+			Description: "Registers a webhook for the given event types (all when empty). The signing secret is generated " +
+				"unless supplied and is only returned by this call. Deliveries are POSTed as JSON with an " +
+				"X-Webhook-Signature header of the form t=<unix>,v1=<hex HMAC-SHA256 of \"<t>.<body>\">.",
+			Body: dto.CreateWebhookRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusCreated, Body: dto.WebhookResponse{}},
+				validationError, unprocessable, unauthorized, internalError,
+			},
+		}, hs.admin(hs.webhooks.Create)},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/webhooks", ID: "listWebhooks", Tags: []string{"webhooks"},
+			Summary:   "List webhooks",
+			Responses: []openapi.Response{{Status: http.StatusOK, Body: []dto.WebhookResponse{}}, unauthorized, internalError},
+		}, hs.admin(hs.webhooks.List)},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/webhooks/dead-letters", ID: "listDeadLetters", Tags: []string{"webhooks"},
+			Summary:   "List deliveries that exhausted their retries",
+			Responses: []openapi.Response{{Status: http.StatusOK, Body: []dto.WebhookDeliveryResponse{}}, unauthorized, internalError},
+		}, hs.admin(hs.webhooks.DeadLetters)},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/webhooks/deliveries/:id/retry", ID: "retryDelivery", Tags: []string{"webhooks"},
+			Summary: "Requeue a dead delivery",
+			Params:  dto.WebhookIDParams{},
+			Responses: []openapi.Response{
+				{Status: http.StatusAccepted},
+				validationError,
+				{Status: http.StatusConflict, Description: "Delivery is not dead", Body: apperror.ErrorPayload{}},
+				unauthorized, internalError,
+			},
+		}, hs.admin(hs.webhooks.Retry)},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/webhooks/:id", ID: "getWebhook", Tags: []string{"webhooks"},
+			Summary: "Get webhook",
+			Params:  dto.WebhookIDParams{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.WebhookResponse{}},
+				validationError, notFound, unauthorized, internalError,
+			},
+		}, hs.admin(hs.webhooks.Get)},
+		{openapi.Operation{
+			Method: http.MethodPut, Path: "/webhooks/:id", ID: "updateWebhook", Tags: []string{"webhooks"},
+			Summary: "Update webhook",
+			Params:  dto.WebhookIDParams{},
+			Body:    dto.UpdateWebhookRequest{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: dto.WebhookResponse{}},
+				validationError, notFound, unprocessable, unauthorized, internalError,
+			},
+		}, hs.admin(hs.webhooks.Update)},
+		{openapi.Operation{
+			Method: http.MethodDelete, Path: "/webhooks/:id", ID: "deleteWebhook", Tags: []string{"webhooks"},
+			Summary:   "Delete webhook",
+			Params:    dto.WebhookIDParams{},
+			Responses: []openapi.Response{noContent, validationError, notFound, unauthorized, internalError},
+		}, hs.admin(hs.webhooks.Delete)},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/webhooks/:id/deliveries", ID: "listWebhookDeliveries", Tags: []string{"webhooks"},
+			Summary: "Delivery log of a webhook, newest first",
+			Params:  dto.WebhookDeliveriesQuery{},
+			Responses: []openapi.Response{
+				{Status: http.StatusOK, Body: []dto.WebhookDeliveryResponse{}},
+				validationError, notFound, unauthorized, internalError,
+			},
+		}, hs.admin(hs.webhooks.Deliveries)},
+	}
+}
+
+func operations(routes []route) []openapi.Operation {
+	ops := make([]openapi.Operation, len(routes))
+	for i, r := range routes {
+		ops[i] = r.Operation
+	}
+	return ops
+}
+
+// Spec returns the OpenAPI document generated from the route table.
+func Spec() *openapi.Document {
+	doc := openapi.Build(info, operations(handlerSet{}.routes(apiVersions(&options{}))))
+	doc.Servers = []openapi.Server{{URL: "http://localhost:8080"}}
+	return doc
+}
@@ -0,0 +1,56 @@
+package routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go-backend/internal/dto"
+	"go-backend/internal/middlewares"
+)
+
+// apiVersion mounts the versioned routes under prefix. Versions share the
+// handlers and differ only in the mapper that renders devices, so a new
+// version is a new mapper plus an entry in apiVersions.
+type apiVersion struct {
+	prefix   string
+	idSuffix string
+	mapper   dto.DeviceMapper
+
+	// A deprecated version announces its deprecation and sunset dates on
+	// every response and links to the same path under successor.
+	deprecated          bool
+	deprecation, sunset time.Time
+	successor           string
+}
+
+func apiVersions(o *options) []apiVersion {
+	return []apiVersion{
+		{prefix: "/v1", mapper: dto.V1{}},
+		// The unprefixed paths predate versioning and alias /v1.
+		{
+			prefix: "", idSuffix: "Unversioned", mapper: dto.V1{},
+			deprecated: true, deprecation: o.unversionedDeprecation, sunset: o.unversionedSunset, successor: "/v1",
+		},
+	}
+}
+
+// mount documents routes as served by this version.
+func (v apiVersion) mount(routes []route) []route {
+	for i := range routes {
+		routes[i].Path = v.prefix + routes[i].Path
+		routes[i].ID += v.idSuffix
+		routes[i].Deprecated = v.deprecated
+	}
+	return routes
+}
+
+// register serves routes under the version prefix.
+func (v apiVersion) register(r *gin.Engine, routes []route) {
+	g := r.Group(v.prefix)
+	if v.deprecated {
+		g.Use(middlewares.Deprecation(v.deprecation, v.sunset, v.successor))
+	}
+	for _, rt := range routes {
+		g.Handle(rt.Method, rt.Path, rt.handler)
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"go-backend/internal/events"
+	"go-backend/internal/models"
+	"go-backend/internal/repositories"
+	"strings"
+)
+
+type BrandService struct {
+	repo    *repositories.BrandRepository
+	devices *DeviceService
+}
+
+// NewBrandService manages brands; devices drops renamed devices from its
+// cache and publishes their changes.
+func NewBrandService(r *repositories.BrandRepository, devices *DeviceService) *BrandService {
+	return &BrandService{repo: r, devices: devices}
+}
+
+func (s *BrandService) List(ctx context.Context) ([]models.BrandWithAliases, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *BrandService) Get(ctx context.Context, id int64) (*models.BrandWithAliases, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *BrandService) Create(ctx context.Context, name string, aliases []string) (*models.BrandWithAliases, error) {
+	id, err := s.repo.Create(ctx, strings.Join(strings.Fields(name), " "), aliases)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, id)
+}
+
+// Update renames a brand and replaces its aliases; its devices take the new
+// name and are published as updated.
+func (s *BrandService) Update(ctx context.Context, id int64, name string, aliases []string) (*models.BrandWithAliases, error) {
+	changed, err := s.repo.Update(ctx, id, strings.Join(strings.Fields(name), " "), aliases)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(changed)
+	return s.repo.Get(ctx, id)
+}
+
+// Delete removes a brand no device references.
+func (s *BrandService) Delete(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Normalize resolves the free-text brand of every device through the brand
+// names and aliases; see BrandRepository.Normalize. Changed devices are
+// published as updated to this process only: other instances keep serving
+// cached devices until device_cache.ttl expires and see the changes through
+// webhooks, not their event streams.
+func (s *BrandService) Normalize(ctx context.Context) (devices, removed int64, err error) {
+	changed, removed, err := s.repo.Normalize(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	s.notify(changed)
+	return int64(len(changed)), removed, nil
+}
+
+// notify drops rebranded devices from the cache and publishes them.
+func (s *BrandService) notify(changed []models.Device) {
+	for i := range changed {
+		s.devices.notify(events.Updated, &changed[i])
+	}
+}
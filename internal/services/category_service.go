@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"go-backend/internal/models"
+	"go-backend/internal/repositories"
+	"time"
+)
+
+// CategoryService manages the JSON Schemas that device attributes are
+// validated against. A schema applies to later writes only; devices already
+// stored are checked the next time their category or attributes change.
+type CategoryService struct {
+	repo *repositories.CategoryRepository
+}
+
+func NewCategoryService(r *repositories.CategoryRepository) *CategoryService {
+	return &CategoryService{repo: r}
+}
+
+func (s *CategoryService) List(ctx context.Context) ([]models.CategorySchema, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *CategoryService) Get(ctx context.Context, category string) (*models.CategorySchema, error) {
+	return s.repo.Get(ctx, category)
+}
+
+// Put registers schema for category after checking that it compiles.
+func (s *CategoryService) Put(ctx context.Context, category, schema string) (*models.CategorySchema, error) {
+	if _, err := models.CompileSchema(schema); err != nil {
+		return nil, err
+	}
+	cs := &models.CategorySchema{Category: category, Schema: schema, UpdatedAt: time.Now().UTC()}
+	if err := s.repo.Put(ctx, cs); err != nil {
+		return nil, err
+	}
+	models.ForgetSchema(category)
+	return cs, nil
+}
+
+func (s *CategoryService) Delete(ctx context.Context, category string) error {
+	if err := s.repo.Delete(ctx, category); err != nil {
+		return err
+	}
+	models.ForgetSchema(category)
+	return nil
+}
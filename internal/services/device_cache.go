@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"go-backend/internal/cache"
+	"go-backend/internal/models"
+	"strconv"
+)
+
+// DeviceCache holds full devices by id in front of the repository. The
+// service stores and hands out deep copies, so callers may modify what they
+// receive without touching the cached entry.
+type DeviceCache interface {
+	Get(id int64) (models.Device, bool)
+	Set(id int64, d models.Device)
+	Delete(id int64)
+	Stats() cache.Stats
+}
+
+type DeviceServiceOption func(*DeviceService)
+
+// WithCache reads devices through c. Only this service's writes invalidate
+// it, so other writers to the same database are seen once entries expire.
+func WithCache(c DeviceCache) DeviceServiceOption {
+	return func(s *DeviceService) { s.cache = c }
+}
+
+// CacheStats reports the counters of the device cache, if there is one.
+func (s *DeviceService) CacheStats() (cache.Stats, bool) {
+	if s.cache == nil {
+		return cache.Stats{}, false
+	}
+	return s.cache.Stats(), true
+}
+
+// cachedGet serves a full device from the cache, coalescing concurrent misses
+// for the same id into one repository read. A read that overlaps a write is
+// returned but not cached, since it may predate the write.
+func (s *DeviceService) cachedGet(ctx context.Context, id int64) (*models.Device, error) {
+	if d, ok := s.cache.Get(id); ok {
+		d = d.Clone()
+		return &d, nil
+	}
+	gen := s.generation.Load()
+	v, err, _ := s.loads.Do(strconv.FormatInt(id, 10), func() (any, error) {
+		// Waiters share this read, so one caller going away must not fail it.
+		d, err := s.repo.Get(context.WithoutCancel(ctx), id)
+		if err != nil {
+			return nil, err
+		}
+		if s.generation.Load() == gen {
+			s.cache.Set(id, d.Clone())
+		}
+		return *d, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Every waiter gets its own copy of the shared result.
+	d := v.(models.Device).Clone()
+	return &d, nil
+}
+
+// invalidate drops id after a write attempt, failed or not; later reads no longer join a load that
+// started before it.
+func (s *DeviceService) invalidate(id int64) {
+	if s.cache == nil {
+		return
+	}
+	s.generation.Add(1)
+	s.loads.Forget(strconv.FormatInt(id, 10))
+	s.cache.Delete(id)
+}
@@ -3,26 +3,95 @@ package services
 import (
 	"context"
 	"errors"
+	"go-backend/internal/events"
 	"go-backend/internal/models"
 	"go-backend/internal/repositories"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 type DeviceService struct {
-	repo *repositories.DeviceRepository
+	repo   *repositories.DeviceRepository
+	events *events.Broker
+
+	cache      DeviceCache
+	loads      singleflight.Group
+	generation atomic.Uint64
 }
 
-func NewDeviceService(r *repositories.DeviceRepository) *DeviceService {
-	return &DeviceService{repo: r}
+func NewDeviceService(r *repositories.DeviceRepository, opts ...DeviceServiceOption) *DeviceService {
+	s := &DeviceService{repo: r, events: events.NewBroker(events.DefaultReplaySize)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
+// Events returns the broker on which committed device changes are published.
+func (s *DeviceService) Events() *events.Broker { return s.events }
+
 func (s *DeviceService) Create(ctx context.Context, d *models.Device) (int64, error) {
-	return s.repo.Create(ctx, d)
+	if err := d.NormalizeIdentifiers(); err != nil {
+		return 0, err
+	}
+	id, err := s.repo.Create(ctx, d)
+	if err != nil {
+		return 0, err
+	}
+	s.events.Publish(events.Created, *d)
+	return id, nil
+}
+
+// Get reads a device; full reads go through the cache, if there is one.
+func (s *DeviceService) Get(ctx context.Context, id int64, columns ...string) (*models.Device, error) {
+	if s.cache == nil || len(columns) > 0 {
+		return s.repo.Get(ctx, id, columns...)
+	}
+	return s.cachedGet(ctx, id)
+}
+
+// GetBySerial reads the device with a serial number.
+func (s *DeviceService) GetBySerial(ctx context.Context, serial string) (*models.Device, error) {
+	return s.repo.GetByIdentifier(ctx, "serial_number", strings.TrimSpace(serial))
+}
+
+// GetByAssetTag reads the device with an asset tag.
+func (s *DeviceService) GetByAssetTag(ctx context.Context, tag string) (*models.Device, error) {
+	return s.repo.GetByIdentifier(ctx, "asset_tag", strings.TrimSpace(tag))
+}
+
+func (s *DeviceService) List(ctx context.Context, f models.DeviceFilter, columns ...string) ([]models.Device, error) {
+	if err := s.canonicalBrand(ctx, &f.Brand); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, f, columns...)
+}
+func (s *DeviceService) ListPage(ctx context.Context, f models.DeviceFilter, afterID int64, limit int) ([]models.Device, error) {
+	if err := s.canonicalBrand(ctx, &f.Brand); err != nil {
+		return nil, err
+	}
+	return s.repo.ListPage(ctx, f, afterID, limit)
+}
+
+// LastChange returns when any device was last created, changed or deleted.
+func (s *DeviceService) LastChange(ctx context.Context) (time.Time, error) {
+	return s.repo.LastChange(ctx)
 }
-func (s *DeviceService) Get(ctx context.Context, id int64) (*models.Device, error) {
-	return s.repo.Get(ctx, id)
+
+// Stats counts devices grouped as q asks.
+func (s *DeviceService) Stats(ctx context.Context, q models.StatsQuery) ([]models.DeviceCount, error) {
+	if err := s.canonicalBrand(ctx, &q.Brand); err != nil {
+		return nil, err
+	}
+	return s.repo.Stats(ctx, q)
 }
-func (s *DeviceService) List(ctx context.Context, brand, state string) ([]models.Device, error) {
-	return s.repo.List(ctx, brand, state)
+
+// History returns up to limit recorded changes of each device, newest first.
+func (s *DeviceService) History(ctx context.Context, ids []int64, limit int) (map[int64][]models.DeviceChange, error) {
+	return s.repo.History(ctx, ids, limit)
 }
 
 func (s *DeviceService) Update(ctx context.Context, id int64, incoming *models.Device) error {
@@ -33,13 +102,25 @@ func (s *DeviceService) Update(ctx context.Context, id int64, incoming *models.D
 	if !incoming.State.Valid() {
 		return models.ErrInvalidState
 	}
+	if err := s.canonicalBrand(ctx, &incoming.Brand); err != nil {
+		return err
+	}
+	if err := incoming.NormalizeIdentifiers(); err != nil {
+		return err
+	}
 	if !incoming.CreatedAt.Equal(existing.CreatedAt) {
 		return models.ErrCannotUpdateCreated
 	}
 	if existing.State == models.StateInUse && (incoming.Name != existing.Name || incoming.Brand != existing.Brand) {
 		return models.ErrCannotUpdateFields
 	}
-	return s.repo.Update(ctx, id, incoming)
+	d, err := s.repo.Update(ctx, id, incoming)
+	s.invalidate(id)
+	if err != nil {
+		return err
+	}
+	s.events.Publish(events.Updated, *d)
+	return nil
 }
 
 func (s *DeviceService) Patch(ctx context.Context, id int64, fields map[string]any) error {
@@ -47,6 +128,91 @@ func (s *DeviceService) Patch(ctx context.Context, id int64, fields map[string]a
 	if err != nil {
 		return err
 	}
+	if err := s.canonicalFields(ctx, existing, fields); err != nil {
+		return err
+	}
+	if err := checkPatch(existing, fields); err != nil {
+		return err
+	}
+	d, err := s.repo.Patch(ctx, id, fields)
+	s.invalidate(id)
+	if err != nil {
+		return err
+	}
+	s.events.Publish(events.Patched, *d)
+	return nil
+}
+
+// PatchIfUnchanged applies fields computed from the snapshot before, failing
+// with ErrConcurrentModification if the device changed in the meantime.
+func (s *DeviceService) PatchIfUnchanged(ctx context.Context, before *models.Device, fields map[string]any) error {
+	if err := s.canonicalFields(ctx, before, fields); err != nil {
+		return err
+	}
+	if err := checkPatch(before, fields); err != nil {
+		return err
+	}
+	d, err := s.repo.PatchIfUnchanged(ctx, before, fields)
+	s.invalidate(before.ID)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return models.ErrConcurrentModification
+	}
+	s.events.Publish(events.Patched, *d)
+	return nil
+}
+
+// canonicalBrand rewrites a brand alias to its brand's name, so "apple"
+// and "Apple" are the same brand in filters and update rules.
+func (s *DeviceService) canonicalBrand(ctx context.Context, brand *string) error {
+	if *brand == "" {
+		return nil
+	}
+	name, err := s.repo.CanonicalBrand(ctx, *brand)
+	if err != nil {
+		return err
+	}
+	*brand = name
+	return nil
+}
+
+// canonicalFields canonicalizes a patched brand and drops it when it names
+// the brand the device already has. Patched serial numbers and asset tags
+// are normalized, blank ones to NULL.
+func (s *DeviceService) canonicalFields(ctx context.Context, existing *models.Device, fields map[string]any) error {
+	for _, k := range []string{"serial_number", "asset_tag"} {
+		v, ok := fields[k]
+		if !ok {
+			continue
+		}
+		str, isString := v.(string)
+		if v != nil && !isString {
+			return models.ErrInvalidIdentifier
+		}
+		id, err := models.NormalizeIdentifier(&str)
+		if err != nil {
+			return err
+		}
+		fields[k] = id
+	}
+	brand, ok := fields["brand"].(string)
+	if !ok {
+		return nil
+	}
+	if err := s.canonicalBrand(ctx, &brand); err != nil {
+		return err
+	}
+	if brand == existing.Brand {
+		delete(fields, "brand")
+	} else {
+		fields["brand"] = brand
+	}
+	return nil
+}
+
+func checkPatch(existing *models.Device, fields map[string]any) error {
 	if _, ok := fields["created_at"]; ok {
 		return models.ErrCannotUpdateCreated
 	}
@@ -67,7 +233,7 @@ func (s *DeviceService) Patch(ctx context.Context, id int64, fields map[string]a
 			return errors.New("invalid state type")
 		}
 	}
-	return s.repo.Patch(ctx, id, fields)
+	return nil
 }
 
 func (s *DeviceService) Delete(ctx context.Context, id int64) error {
@@ -78,5 +244,41 @@ func (s *DeviceService) Delete(ctx context.Context, id int64) error {
 	if existing.State == models.StateInUse {
 		return models.ErrCannotDeleteInUse
 	}
-	return s.repo.Delete(ctx, id)
+	d, err := s.repo.Delete(ctx, id)
+	s.invalidate(id)
+	if err != nil {
+		return err
+	}
+	s.events.Publish(events.Deleted, *d)
+	return nil
+}
+
+// CheckOut moves an available device to in-use.
+func (s *DeviceService) CheckOut(ctx context.Context, id int64) (*models.Device, error) {
+	return s.transition(ctx, id, models.StateAvailable, models.StateInUse, models.ErrNotAvailable)
+}
+
+// CheckIn returns an in-use device to available.
+func (s *DeviceService) CheckIn(ctx context.Context, id int64) (*models.Device, error) {
+	return s.transition(ctx, id, models.StateInUse, models.StateAvailable, models.ErrNotInUse)
+}
+
+func (s *DeviceService) transition(ctx context.Context, id int64, from, to models.State, errWrongState error) (*models.Device, error) {
+	d, err := s.repo.TransitionState(ctx, id, from, to)
+	s.invalidate(id)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errWrongState
+	}
+	s.events.Publish(events.Patched, *d)
+	return d, nil
+}
+
+// notify drops a device another service changed from the cache and
+// publishes it as that service's transaction wrote it.
+func (s *DeviceService) notify(t events.Type, d *models.Device) {
+	s.invalidate(d.ID)
+	s.events.Publish(t, *d)
 }
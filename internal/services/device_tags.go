@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"go-backend/internal/events"
+	"go-backend/internal/models"
+)
+
+// Tags returns the tags of a device in name order.
+func (s *DeviceService) Tags(ctx context.Context, id int64) ([]string, error) {
+	return s.repo.Tags(ctx, id)
+}
+
+// AddTag tags a device with the normalized form of name.
+func (s *DeviceService) AddTag(ctx context.Context, id int64, name string) error {
+	return s.retag(ctx, id, name, s.repo.AddTag)
+}
+
+// RemoveTag removes the normalized form of name from a device's tags.
+func (s *DeviceService) RemoveTag(ctx context.Context, id int64, name string) error {
+	return s.retag(ctx, id, name, s.repo.RemoveTag)
+}
+
+// ListTags returns every tag in use with the number of devices carrying it.
+func (s *DeviceService) ListTags(ctx context.Context) ([]models.TagCount, error) {
+	return s.repo.ListTags(ctx)
+}
+
+func (s *DeviceService) retag(ctx context.Context, id int64, name string, fn func(context.Context, int64, string) (*models.Device, error)) error {
+	tag, err := models.NormalizeTag(name)
+	if err != nil {
+		return err
+	}
+	d, err := fn(ctx, id, tag)
+	s.invalidate(id)
+	if err != nil || d == nil {
+		return err
+	}
+	s.events.Publish(events.Patched, *d)
+	return nil
+}
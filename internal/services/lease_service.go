@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"go-backend/config"
+	"go-backend/internal/events"
+	"go-backend/internal/models"
+	"go-backend/internal/repositories"
+	"strings"
+	"time"
+)
+
+type LeaseService struct {
+	repo    *repositories.LeaseRepository
+	devices *DeviceService
+	cfg     config.LeasesConfig
+}
+
+// NewLeaseService checks devices out for a limited time; like reservations
+// it tells devices about the state changes it makes.
+func NewLeaseService(r *repositories.LeaseRepository, devices *DeviceService, cfg config.LeasesConfig) *LeaseService {
+	return &LeaseService{repo: r, devices: devices, cfg: cfg}
+}
+
+// Acquire checks an available device out to holder for ttl, or for the
+// default TTL when ttl is zero.
+func (s *LeaseService) Acquire(ctx context.Context, deviceID int64, holder string, ttl time.Duration) (*models.Lease, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+	holder = strings.TrimSpace(holder)
+	ttl, err := s.ttl(ttl)
+	if err != nil || holder == "" {
+		return nil, models.ErrInvalidLease
+	}
+	l := &models.Lease{
+		DeviceID: deviceID, Holder: holder, Status: models.LeaseActive,
+		ExpiresAt: now.Add(ttl), CreatedAt: now,
+	}
+	d, err := s.repo.Acquire(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+	s.devices.notify(events.Patched, d)
+	return l, nil
+}
+
+func (s *LeaseService) Get(ctx context.Context, id int64) (*models.Lease, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// Active returns the lease a device is currently checked out under.
+func (s *LeaseService) Active(ctx context.Context, deviceID int64) (*models.Lease, error) {
+	return s.repo.Active(ctx, deviceID)
+}
+
+// Renew makes an active lease expire ttl from now, or the default TTL from
+// now when ttl is zero.
+func (s *LeaseService) Renew(ctx context.Context, id int64, ttl time.Duration) (*models.Lease, error) {
+	ttl, err := s.ttl(ttl)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	return s.repo.Renew(ctx, id, now.Add(ttl), now)
+}
+
+// Release ends an active lease and returns its device.
+func (s *LeaseService) Release(ctx context.Context, id int64) (*models.Lease, error) {
+	l, changed, err := s.repo.Release(ctx, id, time.Now().UTC().Truncate(time.Second))
+	if err != nil {
+		return nil, err
+	}
+	if changed != nil {
+		s.devices.notify(events.Patched, changed)
+	}
+	return l, nil
+}
+
+// Reap expires the leases that ran out by now and returns their devices.
+func (s *LeaseService) Reap(ctx context.Context, now time.Time) (int, error) {
+	expired, changed, err := s.repo.Reap(ctx, now.UTC().Truncate(time.Second))
+	for i := range changed {
+		s.devices.notify(events.Patched, &changed[i])
+	}
+	return expired, err
+}
+
+// Run reaps expired leases every interval until ctx is cancelled.
+func (s *LeaseService) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if _, err := s.Reap(ctx, time.Now()); err != nil && ctx.Err() == nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *LeaseService) ttl(ttl time.Duration) (time.Duration, error) {
+	if ttl == 0 {
+		return s.cfg.DefaultTTL, nil
+	}
+	if ttl < time.Second || ttl > s.cfg.MaxTTL {
+		return 0, models.ErrInvalidLease
+	}
+	return ttl.Truncate(time.Second), nil
+}
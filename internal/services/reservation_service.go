@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"go-backend/internal/events"
+	"go-backend/internal/models"
+	"go-backend/internal/repositories"
+	"strings"
+	"time"
+)
+
+type ReservationService struct {
+	repo    *repositories.ReservationRepository
+	devices *DeviceService
+}
+
+// NewReservationService books devices; devices is told about the state
+// changes reservations make so its cache and event stream stay current.
+func NewReservationService(r *repositories.ReservationRepository, devices *DeviceService) *ReservationService {
+	return &ReservationService{repo: r, devices: devices}
+}
+
+// Create books a device for holder. A reservation whose window has already
+// begun starts right away.
+func (s *ReservationService) Create(ctx context.Context, deviceID int64, holder string, from, to time.Time) (*models.Reservation, error) {
+	now := time.Now().UTC()
+	from, to = from.UTC().Truncate(time.Second), to.UTC().Truncate(time.Second)
+	holder = strings.TrimSpace(holder)
+	if holder == "" || !to.After(from) || !to.After(now) {
+		return nil, models.ErrInvalidReservation
+	}
+	res := &models.Reservation{
+		DeviceID: deviceID, Holder: holder, StartsAt: from, EndsAt: to,
+		Status: models.ReservationScheduled, CreatedAt: now,
+	}
+	if err := s.repo.Create(ctx, res); err != nil {
+		return nil, err
+	}
+	if from.After(now) {
+		return res, nil
+	}
+	d, err := s.repo.Activate(ctx, res)
+	if err != nil {
+		return nil, err
+	}
+	if d != nil {
+		s.devices.notify(events.Patched, d)
+	}
+	return s.repo.Get(ctx, res.ID)
+}
+
+func (s *ReservationService) Get(ctx context.Context, id int64) (*models.Reservation, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// ListForDevice returns every reservation of a device in start order.
+func (s *ReservationService) ListForDevice(ctx context.Context, deviceID int64) ([]models.Reservation, error) {
+	return s.repo.ListForDevice(ctx, deviceID)
+}
+
+// Cancel withdraws a scheduled reservation or ends an active one now.
+func (s *ReservationService) Cancel(ctx context.Context, id int64) (*models.Reservation, error) {
+	res, changed, err := s.repo.Cancel(ctx, id, time.Now().UTC().Truncate(time.Second))
+	if err != nil {
+		return nil, err
+	}
+	if changed != nil {
+		s.devices.notify(events.Patched, changed)
+	}
+	return res, nil
+}
+
+// Available returns the devices matching f that can be booked for [from, to).
+func (s *ReservationService) Available(ctx context.Context, f models.DeviceFilter, from, to time.Time) ([]models.Device, error) {
+	if !to.After(from) {
+		return nil, models.ErrInvalidWindow
+	}
+	if err := s.devices.canonicalBrand(ctx, &f.Brand); err != nil {
+		return nil, err
+	}
+	return s.repo.Available(ctx, f, from.UTC(), to.UTC(), time.Now().UTC())
+}
+
+// Process ends the reservations that are over and starts those that are
+// due at now, moving their devices out of and into in-use.
+func (s *ReservationService) Process(ctx context.Context, now time.Time) error {
+	ended, err := s.repo.End(ctx, now)
+	s.changed(ended)
+	if err != nil {
+		return err
+	}
+	started, err := s.repo.Start(ctx, now)
+	s.changed(started)
+	return err
+}
+
+func (s *ReservationService) changed(list []models.Device) {
+	for i := range list {
+		s.devices.notify(events.Patched, &list[i])
+	}
+}
+
+// Run processes reservations every interval until ctx is cancelled.
+func (s *ReservationService) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if err := s.Process(ctx, time.Now().UTC()); err != nil && ctx.Err() == nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
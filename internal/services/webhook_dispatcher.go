@@ -0,0 +1,184 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go-backend/config"
+	"go-backend/internal/dto"
+	"go-backend/internal/models"
+	"go-backend/internal/repositories"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	dispatchBatch = 50
+	pruneInterval = time.Hour
+
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+	TimestampHeader = "X-Webhook-Timestamp"
+)
+
+// WebhookDispatcher turns outbox events into deliveries and sends them with
+// retries. Delivery is at-least-once: receivers should deduplicate on the
+// event id.
+type WebhookDispatcher struct {
+	repo   *repositories.WebhookRepository
+	cfg    config.WebhooksConfig
+	egress egress
+	client *http.Client
+	tf     models.TimeFormat
+}
+
+// NewWebhookDispatcher renders the timestamps of payloads in tf, the
+// configured api.time_format.
+func NewWebhookDispatcher(r *repositories.WebhookRepository, cfg config.WebhooksConfig, tf models.TimeFormat) *WebhookDispatcher {
+	e := newEgress(cfg)
+	return &WebhookDispatcher{repo: r, cfg: cfg, egress: e, client: e.client(cfg.Timeout), tf: tf}
+}
+
+// Run processes the outbox every poll interval and prunes it every hour until
+// ctx is cancelled.
+func (d *WebhookDispatcher) Run(ctx context.Context, onError func(error)) {
+	t := time.NewTicker(d.cfg.PollInterval)
+	defer t.Stop()
+	var pruned time.Time
+	for {
+		if err := d.Process(ctx); err != nil && ctx.Err() == nil && onError != nil {
+			onError(err)
+		}
+		if time.Since(pruned) >= pruneInterval {
+			if _, err := d.Prune(ctx, time.Now()); err != nil && ctx.Err() == nil && onError != nil {
+				onError(err)
+			}
+			pruned = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Process runs one dispatch cycle: fan out new outbox events, then send every
+// due delivery concurrently.
+func (d *WebhookDispatcher) Process(ctx context.Context) error {
+	if _, err := d.repo.FanOut(ctx, dispatchBatch); err != nil {
+		return err
+	}
+	// A claimed delivery is not retried before the lease runs out, which
+	// covers the request timeout plus bookkeeping.
+	due, err := d.repo.ClaimDue(ctx, time.Now().UTC(), 2*d.cfg.Timeout, dispatchBatch)
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, len(due))
+	for i := range due {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = d.deliver(ctx, &due[i])
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prune drops outbox events and finished deliveries older than the
+// retention period. Pending and dead deliveries keep their events.
+func (d *WebhookDispatcher) Prune(ctx context.Context, now time.Time) (int64, error) {
+	return d.repo.Prune(ctx, now.UTC().Add(-d.cfg.Retention))
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, item *repositories.DueDelivery) error {
+	body, err := payload(item, d.tf)
+	if err != nil {
+		// A payload that cannot be read never will be; retrying is pointless.
+		return d.repo.MarkFailed(ctx, item.Delivery.ID, 0, "invalid payload: "+err.Error(), time.Now().UTC(), true)
+	}
+	status, sendErr := d.send(ctx, item, body)
+	if sendErr == nil {
+		return d.repo.MarkSucceeded(ctx, item.Delivery.ID, status)
+	}
+	dead := item.Delivery.Attempts >= d.cfg.MaxAttempts
+	next := time.Now().UTC().Add(d.backoff(item.Delivery.Attempts))
+	return d.repo.MarkFailed(ctx, item.Delivery.ID, status, sendErr.Error(), next, dead)
+}
+
+func (d *WebhookDispatcher) send(ctx context.Context, item *repositories.DueDelivery, body []byte) (int, error) {
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, item.Subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	// The allowlist may have changed since the subscription was written.
+	if !d.egress.allowedHost(req.URL.Hostname()) {
+		return 0, models.ErrWebhookHostDenied
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, item.Event.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(item.Delivery.ID, 10))
+	req.Header.Set(TimestampHeader, ts)
+	req.Header.Set(SignatureHeader, "t="+ts+",v1="+Sign(item.Subscription.Secret, ts, body))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff doubles the initial delay per attempt up to the maximum and adds up
+// to 20% jitter so failing receivers are not hit in lockstep.
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.MaxBackoff
+	if attempts < 32 {
+		if b := d.cfg.InitialBackoff << (attempts - 1); b > 0 && b < delay {
+			delay = b
+		}
+	}
+	return delay + time.Duration(rand.Int64N(int64(delay)/5+1))
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed by secret.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func payload(item *repositories.DueDelivery, tf models.TimeFormat) ([]byte, error) {
+	var dev models.Device
+	if err := json.Unmarshal([]byte(item.Event.Payload), &dev); err != nil {
+		return nil, err
+	}
+	return json.Marshal(dto.WebhookPayload{
+		EventID:    item.Event.ID,
+		Type:       item.Event.EventType,
+		OccurredAt: dto.NewTimestamp(item.Event.CreatedAt, tf),
+		Device:     dto.FromModel(&dev, tf),
+	})
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"fmt"
+	"go-backend/config"
+	"net"
+	"net/http"
+	"net/netip"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// egress decides which targets webhooks may call. Host names are checked
+// against the allowlist when a subscription is written and before every
+// delivery; addresses are checked when connecting, so a name that resolves
+// to an internal address is refused as well.
+type egress struct {
+	hosts   []string
+	private bool
+}
+
+func newEgress(cfg config.WebhooksConfig) egress {
+	return egress{hosts: cfg.AllowedHosts, private: cfg.AllowPrivateNetworks}
+}
+
+// allowedHost reports whether a URL host may be targeted. An IP literal or
+// localhost is refused when it is internal, even if the allowlist matches.
+func (e egress) allowedHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if !e.private {
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return false
+		}
+		if ip, err := netip.ParseAddr(host); err == nil && internal(ip) {
+			return false
+		}
+	}
+	if len(e.hosts) == 0 {
+		return true
+	}
+	for _, p := range e.hosts {
+		if ok, _ := path.Match(strings.ToLower(p), host); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// client returns an HTTP client that refuses internal addresses unless they
+// are allowed and does not follow redirects, which could lead anywhere.
+func (e egress) client(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	if !e.private {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			ap, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if internal(ap.Addr()) {
+				return fmt.Errorf("webhook target %s is an internal address", ap.Addr())
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// internal reports whether ip is loopback, private, link-local, unspecified
+// or multicast.
+func internal(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
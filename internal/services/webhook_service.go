@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"go-backend/config"
+	"go-backend/internal/events"
+	"go-backend/internal/models"
+	"go-backend/internal/repositories"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const maxDeliveries = 100
+
+type WebhookService struct {
+	repo   *repositories.WebhookRepository
+	egress egress
+}
+
+// NewWebhookService manages subscriptions; target URLs must pass the egress
+// rules of cfg.
+func NewWebhookService(r *repositories.WebhookRepository, cfg config.WebhooksConfig) *WebhookService {
+	return &WebhookService{repo: r, egress: newEgress(cfg)}
+}
+
+// Create registers a subscription. When secret is empty a random one is
+// generated; the caller is responsible for handing it to the subscriber.
+func (s *WebhookService) Create(ctx context.Context, rawURL string, types []string, secret string, active bool) (*models.WebhookSubscription, error) {
+	sub := &models.WebhookSubscription{Active: active, Secret: secret}
+	if err := s.setTarget(sub, rawURL, types); err != nil {
+		return nil, err
+	}
+	if sub.Secret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		sub.Secret = hex.EncodeToString(b)
+	}
+	if err := s.repo.CreateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *WebhookService) Get(ctx context.Context, id int64) (*models.WebhookSubscription, error) {
+	return s.repo.GetSubscription(ctx, id)
+}
+
+func (s *WebhookService) List(ctx context.Context) ([]models.WebhookSubscription, error) {
+	return s.repo.ListSubscriptions(ctx)
+}
+
+func (s *WebhookService) Update(ctx context.Context, id int64, rawURL string, types []string, active bool) (*models.WebhookSubscription, error) {
+	sub, err := s.repo.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setTarget(sub, rawURL, types); err != nil {
+		return nil, err
+	}
+	sub.Active = active
+	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.repo.GetSubscription(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteSubscription(ctx, id)
+}
+
+// Deliveries returns the most recent deliveries of a subscription, newest first.
+func (s *WebhookService) Deliveries(ctx context.Context, id int64, status string) ([]models.WebhookDelivery, error) {
+	if _, err := s.repo.GetSubscription(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, id, status, maxDeliveries)
+}
+
+// DeadLetters returns deliveries that exhausted their attempts.
+func (s *WebhookService) DeadLetters(ctx context.Context) ([]models.WebhookDelivery, error) {
+	return s.repo.ListDeliveries(ctx, 0, string(models.DeliveryDead), maxDeliveries)
+}
+
+// Retry puts a dead delivery back in the queue.
+func (s *WebhookService) Retry(ctx context.Context, id int64) error {
+	ok, err := s.repo.Requeue(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return models.ErrDeliveryNotRetrying
+	}
+	return nil
+}
+
+func (s *WebhookService) setTarget(sub *models.WebhookSubscription, rawURL string, types []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return models.ErrInvalidWebhookURL
+	}
+	if !s.egress.allowedHost(u.Hostname()) {
+		return models.ErrWebhookHostDenied
+	}
+	for _, t := range types {
+		if !slices.Contains(events.Types, events.Type(t)) {
+			return models.ErrInvalidEventType
+		}
+	}
+	sub.URL = u.String()
+	sub.EventTypes = strings.Join(types, ",")
+	return nil
+}
@@ -0,0 +1,936 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: devices/v1/devices.proto
+
+package devicesv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type State int32
+
+const (
+	State_STATE_UNSPECIFIED State = 0
+	State_STATE_AVAILABLE   State = 1
+	State_STATE_IN_USE      State = 2
+	State_STATE_INACTIVE    State = 3
+)
+
+// Enum value maps for State.
+var (
+	State_name = map[int32]string{
+		0: "STATE_UNSPECIFIED",
+		1: "STATE_AVAILABLE",
+		2: "STATE_IN_USE",
+		3: "STATE_INACTIVE",
+	}
+	State_value = map[string]int32{
+		"STATE_UNSPECIFIED": 0,
+		"STATE_AVAILABLE":   1,
+		"STATE_IN_USE":      2,
+		"STATE_INACTIVE":    3,
+	}
+)
+
+func (x State) Enum() *State {
+	p := new(State)
+	*p = x
+	return p
+}
+
+func (x State) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (State) Descriptor() protoreflect.EnumDescriptor {
+	return file_devices_v1_devices_proto_enumTypes[0].Descriptor()
+}
+
+func (State) Type() protoreflect.EnumType {
+	return &file_devices_v1_devices_proto_enumTypes[0]
+}
+
+func (x State) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use State.Descriptor instead.
+func (State) EnumDescriptor() ([]byte, []int) {
+	return file_devices_v1_devices_proto_rawDescGZIP(), []int{0}
+}
+
+type DeviceEvent_Type int32
+
+const (
+	DeviceEvent_TYPE_UNSPECIFIED DeviceEvent_Type = 0
+	DeviceEvent_TYPE_CREATED     DeviceEvent_Type = 1
+	DeviceEvent_TYPE_UPDATED     DeviceEvent_Type = 2
+	DeviceEvent_TYPE_PATCHED     DeviceEvent_Type = 3
+	DeviceEvent_TYPE_DELETED     DeviceEvent_Type = 4
+	// The requested last_event_id is no longer buffered; refetch with
+	// ListDevices before relying on subsequent events.
+	DeviceEvent_TYPE_RESET DeviceEvent_Type = 5
+)
+
+// Enum value maps for DeviceEvent_Type.
+var (
+	DeviceEvent_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_CREATED",
+		2: "TYPE_UPDATED",
+		3: "TYPE_PATCHED",
+		4: "TYPE_DELETED",
+		5: "TYPE_RESET",
+	}
+	DeviceEvent_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_CREATED":     1,
+		"TYPE_UPDATED":     2,
+		"TYPE_PATCHED":     3,
+		"TYPE_DELETED":     4,
+		"TYPE_RESET":       5,
+	}
+)
+
+func (x DeviceEvent_Type) Enum() *DeviceEvent_Type {
+	p := new(DeviceEvent_Type)
+	*p = x
+	return p
+}
+
+func (x DeviceEvent_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DeviceEvent_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_devices_v1_devices_proto_enumTypes[1].Descriptor()
+}
+
+func (DeviceEvent_Type) Type() protoreflect.EnumType {
+	return &file_devices_v1_devices_proto_enumTypes[1]
+}
+
+func (x DeviceEvent_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DeviceEvent_Type.Descriptor instead.
+func (DeviceEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return file_devices_v1_devices_proto_rawDescGZIP(), []int{9, 0}
+}
+
+type Device struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Brand         string                 `protobuf:"bytes,3,opt,name=brand,proto3" json:"brand,omitempty"`
+	State         State                  `protobuf:"varint,4,opt,name=state,proto3,enum=devices.v1.State" json:"state,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Device) Reset() {
+	*x = Device{}
+	mi := &file_devices_v1_devices_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Device) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Device) ProtoMessage() {}
+
+func (x *Device) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_v1_devices_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Device.ProtoReflect.Descriptor instead.
+func (*Device) Descriptor() ([]byte, []int) {
+	return file_devices_v1_devices_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Device) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Device) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Device) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *Device) GetState() State {
+	if x != nil {
+		return x.State
+	}
+	return State_STATE_UNSPECIFIED
+}
+
+func (x *Device) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type CreateDeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Brand         string                 `protobuf:"bytes,2,opt,name=brand,proto3" json:"brand,omitempty"`
+	State         State                  `protobuf:"varint,3,opt,name=state,proto3,enum=devices.v1.State" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDeviceRequest) Reset() {
+	*x = CreateDeviceRequest{}
+	mi := &file_devices_v1_devices_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDeviceRequest) ProtoMessage() {}
+
+func (x *CreateDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_v1_devices_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDeviceRequest.ProtoReflect.Descriptor instead.
+func (*CreateDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_devices_v1_devices_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateDeviceRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateDeviceRequest) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *CreateDeviceRequest) GetState() State {
+	if x != nil {
+		return x.State
+	}
+	return State_STATE_UNSPECIFIED
+}
+
+type GetDeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeviceRequest) Reset() {
+	*x = GetDeviceRequest{}
+	mi := &file_devices_v1_devices_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeviceRequest) ProtoMessage() {}
+
+func (x *GetDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_v1_devices_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeviceRequest.ProtoReflect.Descriptor instead.
+func (*GetDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_devices_v1_devices_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetDeviceRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListDevicesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Brand string                 `protobuf:"bytes,1,opt,name=brand,proto3" json:"brand,omitempty"`
+	State State                  `protobuf:"varint,2,opt,name=state,proto3,enum=devices.v1.State" json:"state,omitempty"`
+	// Defaults to 50, capped at 500.
+	PageSize      int32  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDevicesRequest) Reset() {
+	*x = ListDevicesRequest{}
+	mi := &file_devices_v1_devices_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDevicesRequest) ProtoMessage() {}
+
+func (x *ListDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_v1_devices_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDevicesRequest.ProtoReflect.Descriptor instead.
+func (*ListDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_devices_v1_devices_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListDevicesRequest) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *ListDevicesRequest) GetState() State {
+	if x != nil {
+		return x.State
+	}
+	return State_STATE_UNSPECIFIED
+}
+
+func (x *ListDevicesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListDevicesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListDevicesResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Devices []*Device              `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"`
+	// Empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDevicesResponse) Reset() {
+	*x = ListDevicesResponse{}
+	mi := &file_devices_v1_devices_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDevicesResponse) ProtoMessage() {}
+
+func (x *ListDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_v1_devices_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDevicesResponse.ProtoReflect.Descriptor instead.
+func (*ListDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_devices_v1_devices_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListDevicesResponse) GetDevices() []*Device {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+func (x *ListDevicesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type UpdateDeviceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Brand string                 `protobuf:"bytes,3,opt,name=brand,proto3" json:"brand,omitempty"`
+	State State                  `protobuf:"varint,4,opt,name=state,proto3,enum=devices.v1.State" json:"state,omitempty"`
+	// Must match the stored value when set.
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateDeviceRequest) Reset() {
+	*x = UpdateDeviceRequest{}
+	mi := &file_devices_v1_devices_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateDeviceRequest) ProtoMessage() {}
+
+func (x *UpdateDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_v1_devices_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateDeviceRequest.ProtoReflect.Descriptor instead.
+func (*UpdateDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_devices_v1_devices_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateDeviceRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateDeviceRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateDeviceRequest) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *UpdateDeviceRequest) GetState() State {
+	if x != nil {
+		return x.State
+	}
+	return State_STATE_UNSPECIFIED
+}
+
+func (x *UpdateDeviceRequest) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type PatchDeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Brand         *string                `protobuf:"bytes,3,opt,name=brand,proto3,oneof" json:"brand,omitempty"`
+	State         *State                 `protobuf:"varint,4,opt,name=state,proto3,enum=devices.v1.State,oneof" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PatchDeviceRequest) Reset() {
+	*x = PatchDeviceRequest{}
+	mi := &file_devices_v1_devices_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PatchDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PatchDeviceRequest) ProtoMessage() {}
+
+func (x *PatchDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_v1_devices_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PatchDeviceRequest.ProtoReflect.Descriptor instead.
+func (*PatchDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_devices_v1_devices_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PatchDeviceRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PatchDeviceRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *PatchDeviceRequest) GetBrand() string {
+	if x != nil && x.Brand != nil {
+		return *x.Brand
+	}
+	return ""
+}
+
+func (x *PatchDeviceRequest) GetState() State {
+	if x != nil && x.State != nil {
+		return *x.State
+	}
+	return State_STATE_UNSPECIFIED
+}
+
+type DeleteDeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDeviceRequest) Reset() {
+	*x = DeleteDeviceRequest{}
+	mi := &file_devices_v1_devices_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDeviceRequest) ProtoMessage() {}
+
+func (x *DeleteDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_v1_devices_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDeviceRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_devices_v1_devices_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteDeviceRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type WatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only devices with these ids; all devices when empty.
+	DeviceIds []int64 `protobuf:"varint,1,rep,packed,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"`
+	Brand     string  `protobuf:"bytes,2,opt,name=brand,proto3" json:"brand,omitempty"`
+	State     State   `protobuf:"varint,3,opt,name=state,proto3,enum=devices.v1.State" json:"state,omitempty"`
+	// An id from a DeviceEvent; ids from before a restart or from another
+	// instance are answered with TYPE_RESET.
+	LastEventId   string `protobuf:"bytes,5,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_devices_v1_devices_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_v1_devices_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_devices_v1_devices_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WatchRequest) GetDeviceIds() []int64 {
+	if x != nil {
+		return x.DeviceIds
+	}
+	return nil
+}
+
+func (x *WatchRequest) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *WatchRequest) GetState() State {
+	if x != nil {
+		return x.State
+	}
+	return State_STATE_UNSPECIFIED
+}
+
+func (x *WatchRequest) GetLastEventId() string {
+	if x != nil {
+		return x.LastEventId
+	}
+	return ""
+}
+
+type DeviceEvent struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Type       DeviceEvent_Type       `protobuf:"varint,2,opt,name=type,proto3,enum=devices.v1.DeviceEvent_Type" json:"type,omitempty"`
+	Device     *Device                `protobuf:"bytes,3,opt,name=device,proto3" json:"device,omitempty"`
+	OccurredAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	// "<epoch>-<sequence>"; the epoch changes whenever the server restarts.
+	Id            string `protobuf:"bytes,5,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceEvent) Reset() {
+	*x = DeviceEvent{}
+	mi := &file_devices_v1_devices_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceEvent) ProtoMessage() {}
+
+func (x *DeviceEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_devices_v1_devices_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceEvent.ProtoReflect.Descriptor instead.
+func (*DeviceEvent) Descriptor() ([]byte, []int) {
+	return file_devices_v1_devices_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeviceEvent) GetType() DeviceEvent_Type {
+	if x != nil {
+		return x.Type
+	}
+	return DeviceEvent_TYPE_UNSPECIFIED
+}
+
+func (x *DeviceEvent) GetDevice() *Device {
+	if x != nil {
+		return x.Device
+	}
+	return nil
+}
+
+func (x *DeviceEvent) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *DeviceEvent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+var File_devices_v1_devices_proto protoreflect.FileDescriptor
+
+const file_devices_v1_devices_proto_rawDesc = "" +
+	"\n" +
+	"\x18devices/v1/devices.proto\x12\n" +
+	"devices.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa6\x01\n" +
+	"\x06Device\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05brand\x18\x03 \x01(\tR\x05brand\x12'\n" +
+	"\x05state\x18\x04 \x01(\x0e2\x11.devices.v1.StateR\x05state\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"h\n" +
+	"\x13CreateDeviceRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05brand\x18\x02 \x01(\tR\x05brand\x12'\n" +
+	"\x05state\x18\x03 \x01(\x0e2\x11.devices.v1.StateR\x05state\"\"\n" +
+	"\x10GetDeviceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x8f\x01\n" +
+	"\x12ListDevicesRequest\x12\x14\n" +
+	"\x05brand\x18\x01 \x01(\tR\x05brand\x12'\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x11.devices.v1.StateR\x05state\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\"k\n" +
+	"\x13ListDevicesResponse\x12,\n" +
+	"\adevices\x18\x01 \x03(\v2\x12.devices.v1.DeviceR\adevices\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xb3\x01\n" +
+	"\x13UpdateDeviceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05brand\x18\x03 \x01(\tR\x05brand\x12'\n" +
+	"\x05state\x18\x04 \x01(\x0e2\x11.devices.v1.StateR\x05state\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xa3\x01\n" +
+	"\x12PatchDeviceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\x04name\x18\x02 \x01(\tH\x00R\x04name\x88\x01\x01\x12\x19\n" +
+	"\x05brand\x18\x03 \x01(\tH\x01R\x05brand\x88\x01\x01\x12,\n" +
+	"\x05state\x18\x04 \x01(\x0e2\x11.devices.v1.StateH\x02R\x05state\x88\x01\x01B\a\n" +
+	"\x05_nameB\b\n" +
+	"\x06_brandB\b\n" +
+	"\x06_state\"%\n" +
+	"\x13DeleteDeviceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x96\x01\n" +
+	"\fWatchRequest\x12\x1d\n" +
+	"\n" +
+	"device_ids\x18\x01 \x03(\x03R\tdeviceIds\x12\x14\n" +
+	"\x05brand\x18\x02 \x01(\tR\x05brand\x12'\n" +
+	"\x05state\x18\x03 \x01(\x0e2\x11.devices.v1.StateR\x05state\x12\"\n" +
+	"\rlast_event_id\x18\x05 \x01(\tR\vlastEventIdJ\x04\b\x04\x10\x05\"\xb4\x02\n" +
+	"\vDeviceEvent\x120\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x1c.devices.v1.DeviceEvent.TypeR\x04type\x12*\n" +
+	"\x06device\x18\x03 \x01(\v2\x12.devices.v1.DeviceR\x06device\x12;\n" +
+	"\voccurred_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x12\x0e\n" +
+	"\x02id\x18\x05 \x01(\tR\x02id\"t\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fTYPE_CREATED\x10\x01\x12\x10\n" +
+	"\fTYPE_UPDATED\x10\x02\x12\x10\n" +
+	"\fTYPE_PATCHED\x10\x03\x12\x10\n" +
+	"\fTYPE_DELETED\x10\x04\x12\x0e\n" +
+	"\n" +
+	"TYPE_RESET\x10\x05J\x04\b\x01\x10\x02*Y\n" +
+	"\x05State\x12\x15\n" +
+	"\x11STATE_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fSTATE_AVAILABLE\x10\x01\x12\x10\n" +
+	"\fSTATE_IN_USE\x10\x02\x12\x12\n" +
+	"\x0eSTATE_INACTIVE\x10\x032\xf2\x03\n" +
+	"\rDeviceService\x12C\n" +
+	"\fCreateDevice\x12\x1f.devices.v1.CreateDeviceRequest\x1a\x12.devices.v1.Device\x12=\n" +
+	"\tGetDevice\x12\x1c.devices.v1.GetDeviceRequest\x1a\x12.devices.v1.Device\x12N\n" +
+	"\vListDevices\x12\x1e.devices.v1.ListDevicesRequest\x1a\x1f.devices.v1.ListDevicesResponse\x12C\n" +
+	"\fUpdateDevice\x12\x1f.devices.v1.UpdateDeviceRequest\x1a\x12.devices.v1.Device\x12A\n" +
+	"\vPatchDevice\x12\x1e.devices.v1.PatchDeviceRequest\x1a\x12.devices.v1.Device\x12G\n" +
+	"\fDeleteDevice\x12\x1f.devices.v1.DeleteDeviceRequest\x1a\x16.google.protobuf.Empty\x12<\n" +
+	"\x05Watch\x12\x18.devices.v1.WatchRequest\x1a\x17.devices.v1.DeviceEvent0\x01B(Z&go-backend/pkg/pb/devices/v1;devicesv1b\x06proto3"
+
+var (
+	file_devices_v1_devices_proto_rawDescOnce sync.Once
+	file_devices_v1_devices_proto_rawDescData []byte
+)
+
+func file_devices_v1_devices_proto_rawDescGZIP() []byte {
+	file_devices_v1_devices_proto_rawDescOnce.Do(func() {
+		file_devices_v1_devices_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_devices_v1_devices_proto_rawDesc), len(file_devices_v1_devices_proto_rawDesc)))
+	})
+	return file_devices_v1_devices_proto_rawDescData
+}
+
+var file_devices_v1_devices_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_devices_v1_devices_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_devices_v1_devices_proto_goTypes = []any{
+	(State)(0),                    // 0: devices.v1.State
+	(DeviceEvent_Type)(0),         // 1: devices.v1.DeviceEvent.Type
+	(*Device)(nil),                // 2: devices.v1.Device
+	(*CreateDeviceRequest)(nil),   // 3: devices.v1.CreateDeviceRequest
+	(*GetDeviceRequest)(nil),      // 4: devices.v1.GetDeviceRequest
+	(*ListDevicesRequest)(nil),    // 5: devices.v1.ListDevicesRequest
+	(*ListDevicesResponse)(nil),   // 6: devices.v1.ListDevicesResponse
+	(*UpdateDeviceRequest)(nil),   // 7: devices.v1.UpdateDeviceRequest
+	(*PatchDeviceRequest)(nil),    // 8: devices.v1.PatchDeviceRequest
+	(*DeleteDeviceRequest)(nil),   // 9: devices.v1.DeleteDeviceRequest
+	(*WatchRequest)(nil),          // 10: devices.v1.WatchRequest
+	(*DeviceEvent)(nil),           // 11: devices.v1.DeviceEvent
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),         // 13: google.protobuf.Empty
+}
+var file_devices_v1_devices_proto_depIdxs = []int32{
+	0,  // 0: devices.v1.Device.state:type_name -> devices.v1.State
+	12, // 1: devices.v1.Device.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 2: devices.v1.CreateDeviceRequest.state:type_name -> devices.v1.State
+	0,  // 3: devices.v1.ListDevicesRequest.state:type_name -> devices.v1.State
+	2,  // 4: devices.v1.ListDevicesResponse.devices:type_name -> devices.v1.Device
+	0,  // 5: devices.v1.UpdateDeviceRequest.state:type_name -> devices.v1.State
+	12, // 6: devices.v1.UpdateDeviceRequest.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 7: devices.v1.PatchDeviceRequest.state:type_name -> devices.v1.State
+	0,  // 8: devices.v1.WatchRequest.state:type_name -> devices.v1.State
+	1,  // 9: devices.v1.DeviceEvent.type:type_name -> devices.v1.DeviceEvent.Type
+	2,  // 10: devices.v1.DeviceEvent.device:type_name -> devices.v1.Device
+	12, // 11: devices.v1.DeviceEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	3,  // 12: devices.v1.DeviceService.CreateDevice:input_type -> devices.v1.CreateDeviceRequest
+	4,  // 13: devices.v1.DeviceService.GetDevice:input_type -> devices.v1.GetDeviceRequest
+	5,  // 14: devices.v1.DeviceService.ListDevices:input_type -> devices.v1.ListDevicesRequest
+	7,  // 15: devices.v1.DeviceService.UpdateDevice:input_type -> devices.v1.UpdateDeviceRequest
+	8,  // 16: devices.v1.DeviceService.PatchDevice:input_type -> devices.v1.PatchDeviceRequest
+	9,  // 17: devices.v1.DeviceService.DeleteDevice:input_type -> devices.v1.DeleteDeviceRequest
+	10, // 18: devices.v1.DeviceService.Watch:input_type -> devices.v1.WatchRequest
+	2,  // 19: devices.v1.DeviceService.CreateDevice:output_type -> devices.v1.Device
+	2,  // 20: devices.v1.DeviceService.GetDevice:output_type -> devices.v1.Device
+	6,  // 21: devices.v1.DeviceService.ListDevices:output_type -> devices.v1.ListDevicesResponse
+	2,  // 22: devices.v1.DeviceService.UpdateDevice:output_type -> devices.v1.Device
+	2,  // 23: devices.v1.DeviceService.PatchDevice:output_type -> devices.v1.Device
+	13, // 24: devices.v1.DeviceService.DeleteDevice:output_type -> google.protobuf.Empty
+	11, // 25: devices.v1.DeviceService.Watch:output_type -> devices.v1.DeviceEvent
+	19, // [19:26] is the sub-list for method output_type
+	12, // [12:19] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_devices_v1_devices_proto_init() }
+func file_devices_v1_devices_proto_init() {
+	if File_devices_v1_devices_proto != nil {
+		return
+	}
+	file_devices_v1_devices_proto_msgTypes[6].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_devices_v1_devices_proto_rawDesc), len(file_devices_v1_devices_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_devices_v1_devices_proto_goTypes,
+		DependencyIndexes: file_devices_v1_devices_proto_depIdxs,
+		EnumInfos:         file_devices_v1_devices_proto_enumTypes,
+		MessageInfos:      file_devices_v1_devices_proto_msgTypes,
+	}.Build()
+	File_devices_v1_devices_proto = out.File
+	file_devices_v1_devices_proto_goTypes = nil
+	file_devices_v1_devices_proto_depIdxs = nil
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"encoding/base64"
+	"strconv"
+)
+
+// EncodeCursor returns an opaque pagination cursor for the last id of a page.
+func EncodeCursor(lastID int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(lastID, 10)))
+}
+
+// DecodeCursor reverses EncodeCursor; an empty cursor means the first page.
+func DecodeCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(b), 10, 64)
+}
@@ -0,0 +1,168 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-backend/config"
+	"go-backend/database"
+	"go-backend/internal/dto"
+	"go-backend/internal/middlewares"
+	"go-backend/internal/openapi"
+	"go-backend/internal/routers"
+	apperror "go-backend/pkg/error"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestAttributes_SchemasAndFilters(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/attrs.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db)
+	do := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	const phoneSchema = `{"type":"object","required":["imei"],"properties":{` +
+		`"imei":{"type":"string","pattern":"^[0-9]{15}$"},"os_version":{"type":"integer"}}}`
+	if rec := do(http.MethodPut, "/v1/admin/categories/phone/schema", "application/json", phoneSchema); rec.Code != http.StatusOK {
+		t.Fatalf("put schema: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPut, "/v1/admin/categories/monitor/schema", "application/json", `{"type":5}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid schema: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec := do(http.MethodPost, "/v1/devices", "application/json", `{"name":"p0","brand":"Acme","state":"available","category":"phone","attributes":{"os_version":14}}`)
+	var payload apperror.ErrorPayload
+	_ = json.Unmarshal(rec.Body.Bytes(), &payload)
+	if rec.Code != http.StatusUnprocessableEntity || payload.Code != "invalid_attributes" || payload.Details == nil {
+		t.Fatalf("missing imei: %d %s", rec.Code, rec.Body.String())
+	}
+	create := func(body string) string {
+		t.Helper()
+		rec := do(http.MethodPost, "/v1/devices", "application/json", body)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create: %d %s", rec.Code, rec.Body.String())
+		}
+		var d dto.DeviceResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &d)
+		return "/v1/devices/" + strconv.FormatInt(d.ID, 10)
+	}
+	phone := create(`{"name":"p1","brand":"Acme","state":"available","category":"phone","attributes":{"imei":"490154203237518","os_version":14}}`)
+	create(`{"name":"l1","brand":"Acme","state":"available","category":"laptop","attributes":{"os_version":"14","gpu":{"vram":8}}}`)
+	create(`{"name":"x1","brand":"Acme","state":"available"}`)
+
+	names := func(query string) []string {
+		t.Helper()
+		rec := do(http.MethodGet, "/v1/devices?"+query, "", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: %d %s", query, rec.Code, rec.Body.String())
+		}
+		var list []dto.DeviceResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &list)
+		var out []string
+		for _, d := range list {
+			out = append(out, d.Name)
+		}
+		return out
+	}
+	if got := names("attr.os_version=14"); !slices.Equal(got, []string{"p1", "l1"}) {
+		t.Fatalf("attr.os_version: %v", got)
+	}
+	if got := names("category=phone&attr.os_version=14"); !slices.Equal(got, []string{"p1"}) {
+		t.Fatalf("category: %v", got)
+	}
+	if got := names("attr.gpu.vram=8"); !slices.Equal(got, []string{"l1"}) {
+		t.Fatalf("nested: %v", got)
+	}
+	if rec := do(http.MethodGet, "/v1/devices?attr.a..b=1", "", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid attribute path: %d", rec.Code)
+	}
+
+	if rec := do(http.MethodPatch, phone, openapi.MergePatchContentType, `{"attributes":{"os_version":"fifteen"}}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid merge patch: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPatch, phone, openapi.MergePatchContentType, `{"attributes":{"os_version":15}}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("merge patch: %d %s", rec.Code, rec.Body.String())
+	}
+	var d dto.DeviceResponse
+	_ = json.Unmarshal(do(http.MethodGet, phone, "", "").Body.Bytes(), &d)
+	if d.Attributes["imei"] != "490154203237518" || d.Attributes["os_version"] != float64(15) {
+		t.Fatalf("attributes after merge patch: %+v", d.Attributes)
+	}
+
+	var schemas []dto.CategorySchemaResponse
+	_ = json.Unmarshal(do(http.MethodGet, "/v1/admin/categories", "", "").Body.Bytes(), &schemas)
+	if len(schemas) != 1 || schemas[0].Category != "phone" || schemas[0].Schema["type"] != "object" {
+		t.Fatalf("schemas: %+v", schemas)
+	}
+	if rec := do(http.MethodDelete, "/v1/admin/categories/phone/schema", "", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete schema: %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/v1/admin/categories/phone/schema", "", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("deleted schema: %d", rec.Code)
+	}
+	if rec := do(http.MethodPatch, phone, "application/json", `{"attributes":{}}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("unvalidated patch: %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAttributes_AdminRequiresAuth(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/admin.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const secret = "0123456789abcdef0123456789abcdef"
+	r := newRouter(db, routers.WithAuth(config.AuthConfig{Enabled: true, JWTSecret: secret, APIKeys: []string{"k1"}}))
+	get := func(header, value string) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/admin/categories", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := get("", ""); code != http.StatusUnauthorized {
+		t.Fatalf("anonymous: expected 401, got %d", code)
+	}
+	if code := get(middlewares.APIKeyHeader, "nope"); code != http.StatusUnauthorized {
+		t.Fatalf("wrong key: expected 401, got %d", code)
+	}
+	if code := get(middlewares.APIKeyHeader, "k1"); code != http.StatusOK {
+		t.Fatalf("api key: expected 200, got %d", code)
+	}
+	sign := func(key string, exp time.Time) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(exp)}).SignedString([]byte(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + s
+	}
+	if code := get("Authorization", sign(secret, time.Now().Add(time.Minute))); code != http.StatusOK {
+		t.Fatalf("jwt: expected 200, got %d", code)
+	}
+	if code := get("Authorization", sign(secret, time.Now().Add(-time.Minute))); code != http.StatusUnauthorized {
+		t.Fatalf("expired jwt: expected 401, got %d", code)
+	}
+	if code := get("Authorization", sign("another-secret-another-secret-xx", time.Now().Add(time.Minute))); code != http.StatusUnauthorized {
+		t.Fatalf("foreign jwt: expected 401, got %d", code)
+	}
+	// Device routes stay open.
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/devices", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("devices: expected 200, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,217 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"go-backend/config"
+	"go-backend/database"
+	"go-backend/internal/dto"
+	"go-backend/internal/events"
+	"go-backend/internal/middlewares"
+	"go-backend/internal/models"
+	"go-backend/internal/repositories"
+	"go-backend/internal/routers"
+	"go-backend/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBrands_CanonicalNamesAndAliases(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/brands.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db)
+	do := requester(r)
+
+	rec := do(http.MethodPost, "/v1/brands", `{"name":"Apple","aliases":["Apple Inc.","APPL"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create brand: %d %s", rec.Code, rec.Body.String())
+	}
+	var brand dto.BrandResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &brand)
+	brandPath := "/v1/brands/" + strconv.FormatInt(brand.ID, 10)
+	if rec := do(http.MethodPost, "/v1/brands", `{"name":"apple inc."}`); rec.Code != http.StatusConflict {
+		t.Fatalf("alias as name: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = do(http.MethodPost, "/v1/devices", `{"name":"phone","brand":"apple  inc. ","state":"in-use"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create device: %d %s", rec.Code, rec.Body.String())
+	}
+	var d dto.DeviceResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &d)
+	if d.Brand != "Apple" || d.BrandID == nil || *d.BrandID != brand.ID {
+		t.Fatalf("device not canonicalized: %q %v", d.Brand, d.BrandID)
+	}
+	devicePath := "/v1/devices/" + strconv.FormatInt(d.ID, 10)
+	// Naming the same brand differently is not a change, even while in use.
+	if rec := do(http.MethodPatch, devicePath, `{"brand":"APPL"}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("patch alias: %d %s", rec.Code, rec.Body.String())
+	}
+
+	var list []dto.DeviceResponse
+	_ = json.Unmarshal(do(http.MethodGet, "/v1/devices?brand=Apple%20Inc.", "").Body.Bytes(), &list)
+	if len(list) != 1 || list[0].ID != d.ID {
+		t.Fatalf("filter by alias: %+v", list)
+	}
+
+	if rec := do(http.MethodPut, brandPath, `{"name":"Apple Computer","aliases":["Apple","Apple Inc."]}`); rec.Code != http.StatusOK {
+		t.Fatalf("rename: %d %s", rec.Code, rec.Body.String())
+	}
+	_ = json.Unmarshal(do(http.MethodGet, devicePath, "").Body.Bytes(), &d)
+	if d.Brand != "Apple Computer" {
+		t.Fatalf("rename not propagated: %q", d.Brand)
+	}
+	_ = json.Unmarshal(do(http.MethodGet, brandPath, "").Body.Bytes(), &brand)
+	if brand.Devices != 1 || len(brand.Aliases) != 2 {
+		t.Fatalf("unexpected brand: %+v", brand)
+	}
+
+	if rec := do(http.MethodDelete, brandPath, ""); rec.Code != http.StatusConflict {
+		t.Fatalf("delete in use: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodDelete, devicePath, ""); rec.Code != http.StatusConflict {
+		// The device is in use; free it first.
+		t.Fatalf("delete device: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPatch, devicePath, `{"state":"available"}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("release: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodDelete, devicePath, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete device: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodDelete, brandPath, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete brand: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodGet, brandPath, ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("get deleted: %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestBrands_KnownBrandsOnly(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/known-brands.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db,
+		routers.WithBrands(config.BrandsConfig{AutoCreate: false}),
+		routers.WithAuth(config.AuthConfig{Enabled: true, APIKeys: []string{"k1"}}))
+	do := requester(r)
+	admin := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middlewares.APIKeyHeader, "k1")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	code := func(rec *httptest.ResponseRecorder) string {
+		var payload struct{ Code string }
+		_ = json.Unmarshal(rec.Body.Bytes(), &payload)
+		return payload.Code
+	}
+
+	if rec := do(http.MethodPost, "/v1/brands", `{"name":"Acme"}`); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous create brand: expected 401, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/v1/brands", ""); rec.Code != http.StatusOK {
+		t.Fatalf("anonymous list brands: expected 200, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/v1/devices", `{"name":"scope","brand":"Acme","state":"available"}`); rec.Code != http.StatusUnprocessableEntity || code(rec) != "unknown_brand" {
+		t.Fatalf("unknown brand: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := admin(http.MethodPost, "/v1/brands", `{"name":"Acme"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("create brand: %d %s", rec.Code, rec.Body.String())
+	}
+	rec := do(http.MethodPost, "/v1/devices", `{"name":"scope","brand":"acme","state":"available"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("known brand: %d %s", rec.Code, rec.Body.String())
+	}
+	var d dto.DeviceResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &d)
+	if rec := do(http.MethodPatch, "/v1/devices/"+strconv.FormatInt(d.ID, 10), `{"brand":"Acmee"}`); rec.Code != http.StatusUnprocessableEntity || code(rec) != "unknown_brand" {
+		t.Fatalf("typo in patch: %d %s", rec.Code, rec.Body.String())
+	}
+	// A device that predates brands can still change; its brand is created.
+	legacy := models.Device{Name: "old", Brand: "Globex", State: models.StateAvailable}
+	if err := db.Create(&legacy).Error; err != nil {
+		t.Fatal(err)
+	}
+	if rec := do(http.MethodPatch, "/v1/devices/"+strconv.FormatInt(legacy.ID, 10), `{"state":"inactive"}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("patch legacy device: %d %s", rec.Code, rec.Body.String())
+	}
+	var brands int64
+	db.Model(&models.Brand{}).Count(&brands)
+	if brands != 2 {
+		t.Fatalf("expected Acme and Globex, got %d brands", brands)
+	}
+}
+
+func TestBrands_NormalizeFreeText(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/normalize.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Devices written before brands existed carry only free text.
+	for _, b := range []string{"Samsung", "samsung ", "Samsung Electronics", "Acme"} {
+		if err := db.Create(&models.Device{Name: "d", Brand: b, State: models.StateAvailable}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	devices := services.NewDeviceService(repositories.NewDeviceRepository(db))
+	brands := services.NewBrandService(repositories.NewBrandRepository(db), devices)
+	if _, err := brands.Create(ctx, "Samsung Electronics", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := brands.Create(ctx, "Samsung", nil); err != nil {
+		t.Fatal(err)
+	}
+	// Folding "Samsung" into "Samsung Electronics" leaves the former unused.
+	list, err := brands.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range list {
+		if b.Name == "Samsung Electronics" {
+			if _, err := brands.Update(ctx, b.ID, b.Name, []string{"Samsung"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	sub, _, _ := devices.Events().Subscribe("", 8, nil)
+	defer sub.Close()
+	changed, removed, err := brands.Normalize(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed != 4 || removed != 1 {
+		t.Fatalf("changed %d, removed %d", changed, removed)
+	}
+	for range changed {
+		select {
+		case ev := <-sub.C:
+			if ev.Type != events.Updated {
+				t.Fatalf("expected an updated event, got %+v", ev)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("normalized devices were not published")
+		}
+	}
+	var got []models.Device
+	db.Order("id").Find(&got)
+	for i, want := range []string{"Samsung Electronics", "Samsung Electronics", "Samsung Electronics", "Acme"} {
+		if got[i].Brand != want || got[i].BrandID == nil {
+			t.Fatalf("device %d: %q %v", i, got[i].Brand, got[i].BrandID)
+		}
+	}
+	if changed, removed, _ := brands.Normalize(ctx); changed != 0 || removed != 0 {
+		t.Fatalf("second run changed %d, removed %d", changed, removed)
+	}
+}
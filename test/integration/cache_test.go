@@ -0,0 +1,72 @@
+package integration
+
+import (
+	"encoding/json"
+	"go-backend/database"
+	"go-backend/internal/cache"
+	"go-backend/internal/dto"
+	"go-backend/internal/models"
+	"go-backend/internal/repositories"
+	"go-backend/internal/routers"
+	"go-backend/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDeviceCache_ReadThroughAndStats(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/cache.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := services.NewDeviceService(repositories.NewDeviceRepository(db),
+		services.WithCache(cache.NewLRU[int64, models.Device](10, time.Minute)))
+	r := newRouter(db, routers.WithDeviceService(svc))
+	do := requester(r)
+	rec := do(http.MethodPost, "/v1/devices", `{"name":"A","brand":"B","state":"available"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", rec.Code, rec.Body.String())
+	}
+	var created dto.DeviceResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &created)
+	path := "/v1/devices/" + strconv.FormatInt(created.ID, 10)
+	for range 3 {
+		if rec := do(http.MethodGet, path, ""); rec.Code != http.StatusOK {
+			t.Fatalf("get: %d %s", rec.Code, rec.Body.String())
+		}
+	}
+	if rec := do(http.MethodPatch, path, `{"name":"A2"}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("patch: %d %s", rec.Code, rec.Body.String())
+	}
+	var got dto.DeviceResponse
+	_ = json.Unmarshal(do(http.MethodGet, path, "").Body.Bytes(), &got)
+	if got.Name != "A2" {
+		t.Fatalf("stale read after patch: %+v", got)
+	}
+
+	rec = do(http.MethodGet, "/metrics/cache", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stats: %d %s", rec.Code, rec.Body.String())
+	}
+	var stats dto.CacheStatsResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &stats)
+	if !stats.Enabled || stats.Hits < 2 || stats.Misses < 2 || stats.Entries != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestDeviceCache_StatsWithoutCache(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/nocache.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	newRouter(db).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics/cache", nil))
+	var stats dto.CacheStatsResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &stats)
+	if rec.Code != http.StatusOK || stats.Enabled {
+		t.Fatalf("unexpected response: %d %s", rec.Code, rec.Body.String())
+	}
+}
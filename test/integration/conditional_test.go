@@ -0,0 +1,67 @@
+package integration
+
+import (
+	"bytes"
+	"go-backend/config"
+	"go-backend/database"
+	"go-backend/internal/routers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConditionalGet_ETagAndLastModified(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/conditional.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db, routers.WithHTTPCache(config.HTTPCacheConfig{GetDevice: "private, max-age=5", ListDevices: "no-cache"}))
+	do := func(method, target string, header map[string]string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	for range 2 {
+		do(http.MethodPost, "/v1/devices", map[string]string{"Content-Type": "application/json"}, `{"name":"X","brand":"Acme","state":"available"}`)
+	}
+
+	rec := do(http.MethodGet, "/v1/devices/1", nil, "")
+	etag, modified := rec.Header().Get("ETag"), rec.Header().Get("Last-Modified")
+	if rec.Code != http.StatusOK || etag == "" || modified == "" {
+		t.Fatalf("expected validators, got %d %v", rec.Code, rec.Header())
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "private, max-age=5" {
+		t.Fatalf("unexpected Cache-Control %q", got)
+	}
+	if rec := do(http.MethodGet, "/v1/devices/1", map[string]string{"If-None-Match": `"other", ` + etag}, ""); rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("expected 304 for matching ETag, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/v1/devices/1", map[string]string{"If-Modified-Since": modified}, ""); rec.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for If-Modified-Since, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/v1/devices/1?time_format=epoch", map[string]string{"If-None-Match": etag}, ""); rec.Code != http.StatusOK {
+		t.Fatalf("another representation must not match, got %d", rec.Code)
+	}
+	do(http.MethodPatch, "/v1/devices/1", map[string]string{"Content-Type": "application/json"}, `{"state":"inactive"}`)
+	rec = do(http.MethodGet, "/v1/devices/1", map[string]string{"If-None-Match": etag}, "")
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Fatalf("expected a fresh copy after a change, got %d", rec.Code)
+	}
+
+	rec = do(http.MethodGet, "/v1/devices", nil, "")
+	listTag := rec.Header().Get("ETag")
+	if rec.Header().Get("Last-Modified") == "" || rec.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatalf("unexpected list headers %v", rec.Header())
+	}
+	if rec := do(http.MethodGet, "/v1/devices", map[string]string{"If-None-Match": listTag}, ""); rec.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for unchanged list, got %d", rec.Code)
+	}
+	do(http.MethodDelete, "/v1/devices/2", nil, "")
+	if rec := do(http.MethodGet, "/v1/devices", map[string]string{"If-None-Match": listTag}, ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 after a delete, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,91 @@
+package integration
+
+import (
+	"go-backend/config"
+	"go-backend/database"
+	"go-backend/internal/routers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS_Policy(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/cors.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db, routers.WithCORS(config.CORSConfig{
+		AllowedOrigins:        []string{"https://app.example.com"},
+		AllowedOriginPatterns: []string{"https://*.lab.example.com"},
+		AllowedMethods:        []string{"GET", "POST"},
+		AllowedHeaders:        []string{"Content-Type"},
+		ExposedHeaders:        []string{"ETag"},
+		AllowCredentials:      true,
+		MaxAge:                time.Minute,
+	}))
+
+	req := httptest.NewRequest(http.MethodOptions, "/devices", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" || rec.Header().Get("Access-Control-Allow-Credentials") != "true" || rec.Header().Get("Access-Control-Max-Age") != "60" {
+		t.Fatalf("unexpected preflight headers: %v", rec.Header())
+	}
+
+	req = httptest.NewRequest(http.MethodOptions, "/devices", nil)
+	req.Header.Set("Origin", "https://evil.example.org")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("expected 403 without CORS headers, got %d %v", rec.Code, rec.Header())
+	}
+
+	req = httptest.NewRequest(http.MethodOptions, "/devices", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "DELETE")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("expected 403 for a method that is not allowed, got %d %v", rec.Code, rec.Header())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/devices", nil)
+	req.Header.Set("Origin", "https://k1.lab.example.com")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if rec.Header().Get("Access-Control-Allow-Origin") != "https://k1.lab.example.com" || rec.Header().Get("Access-Control-Expose-Headers") != "ETag" || rec.Header().Get("Vary") != "Origin" {
+		t.Fatalf("unexpected headers: %v", rec.Header())
+	}
+}
+
+func TestCORS_DefaultDeniesCrossOrigin(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/cors.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db)
+	req := httptest.NewRequest(http.MethodOptions, "/devices", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/devices", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("unexpected CORS headers: %v", rec.Header())
+	}
+}
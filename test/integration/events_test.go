@@ -0,0 +1,97 @@
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"go-backend/database"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readEvent(t *testing.T, sc *bufio.Scanner) (id, event, data string) {
+	t.Helper()
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			if event != "" {
+				return
+			}
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	t.Fatalf("stream ended: %v", sc.Err())
+	return
+}
+
+func TestEvents_StreamAndResume(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/events.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(newRouter(db))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/devices/events?state=inactive", nil)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	for _, body := range []string{
+		`{"name":"A","brand":"Acme","state":"available"}`,
+		`{"name":"B","brand":"Acme","state":"inactive"}`,
+	} {
+		res, err := http.Post(srv.URL+"/devices", "application/json", bytes.NewBufferString(body))
+		if err != nil || res.StatusCode != http.StatusCreated {
+			t.Fatalf("create failed: %v", err)
+		}
+		res.Body.Close()
+	}
+	sc := bufio.NewScanner(res.Body)
+	id, event, data := readEvent(t, sc)
+	epoch, seq, _ := strings.Cut(id, "-")
+	if seq != "2" || event != "created" || !strings.Contains(data, `"name":"B"`) {
+		t.Fatalf("unexpected event %s %s %s", id, event, data)
+	}
+
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/devices/events", nil)
+	req.Header.Set("Last-Event-ID", epoch+"-1")
+	res2, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res2.Body.Close()
+	id, event, _ = readEvent(t, bufio.NewScanner(res2.Body))
+	if id != epoch+"-2" || event != "created" {
+		t.Fatalf("expected replay of event 2, got %s %s", id, event)
+	}
+
+	// Positions past the end, and positions issued before a restart, reset.
+	for _, last := range []string{epoch + "-99", "PREVIOUS-1"} {
+		req, _ = http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/devices/events", nil)
+		req.Header.Set("Last-Event-ID", last)
+		res3, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res3.Body.Close()
+		if _, event, _ = readEvent(t, bufio.NewScanner(res3.Body)); event != "reset" {
+			t.Fatalf("expected reset event for %s, got %s", last, event)
+		}
+	}
+}
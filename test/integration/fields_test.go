@@ -0,0 +1,67 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-backend/database"
+	"go-backend/internal/dto"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestFields_SparseFieldsets(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/fields.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db)
+	req := httptest.NewRequest(http.MethodPost, "/v1/devices", bytes.NewBufferString(`{"name":"X","brand":"Acme","state":"available"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	keys := func(obj map[string]any) []string {
+		var out []string
+		for k := range obj {
+			out = append(out, k)
+		}
+		slices.Sort(out)
+		return out
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/devices?fields=id,name,state,name", nil))
+	var list []map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list) != 1 {
+		t.Fatalf("unexpected list %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := keys(list[0]); !slices.Equal(got, []string{"id", "name", "state"}) {
+		t.Fatalf("unexpected fields %v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/devices/1?fields=brand", nil))
+	var one map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &one)
+	if rec.Code != http.StatusOK || len(one) != 1 || one["brand"] != "Acme" {
+		t.Fatalf("unexpected device %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/devices/1", nil))
+	one = nil
+	_ = json.Unmarshal(rec.Body.Bytes(), &one)
+	if want := slices.Sorted(maps.Keys(dto.V1{}.Columns())); !slices.Equal(keys(one), want) {
+		t.Fatalf("expected every field %v without fields=: %s", want, rec.Body.String())
+	}
+
+	for _, q := range []string{"fields=id,secret", "fields=id,,name"} {
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/devices?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", q, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-backend/database"
+	"go-backend/internal/models"
+	"go-backend/internal/routers"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type gqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func TestGraphQL(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/graphql.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db, routers.WithGraphQLLimits(4, 50))
+	do := func(query string, vars map[string]any) gqlResponse {
+		t.Helper()
+		body, _ := json.Marshal(map[string]any{"query": query, "variables": vars})
+		req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp gqlResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	errCode := func(resp gqlResponse) string {
+		if len(resp.Errors) == 0 {
+			return ""
+		}
+		code, _ := resp.Errors[0].Extensions["code"].(string)
+		return code
+	}
+
+	for _, name := range []string{"A", "B", "C"} {
+		resp := do(`mutation($name: String!) { createDevice(input: {name: $name, brand: "Acme", state: AVAILABLE}) { id } }`, map[string]any{"name": name})
+		if len(resp.Errors) > 0 {
+			t.Fatalf("create failed: %+v", resp.Errors)
+		}
+	}
+	if resp := do(`mutation { checkOut(id: "1") { state } }`, nil); string(resp.Data) != `{"checkOut":{"state":"IN_USE"}}` {
+		t.Fatalf("unexpected checkOut result: %s %+v", resp.Data, resp.Errors)
+	}
+	if resp := do(`mutation { deleteDevice(id: "1") }`, nil); errCode(resp) != "in_use_delete_blocked" {
+		t.Fatalf("expected in_use_delete_blocked, got %+v", resp.Errors)
+	}
+
+	resp := do(`{ devices(filter: {brand: "Acme"}, first: 2) { nodes { name } pageInfo { endCursor hasNextPage } } }`, nil)
+	var page struct {
+		Devices struct {
+			Nodes    []struct{ Name string }
+			PageInfo struct {
+				EndCursor   string
+				HasNextPage bool
+			}
+		}
+	}
+	_ = json.Unmarshal(resp.Data, &page)
+	if len(page.Devices.Nodes) != 2 || !page.Devices.PageInfo.HasNextPage {
+		t.Fatalf("unexpected first page: %s", resp.Data)
+	}
+	resp = do(`query($after: String) { devices(first: 2, after: $after) { nodes { name } pageInfo { hasNextPage } } }`, map[string]any{"after": page.Devices.PageInfo.EndCursor})
+	if string(resp.Data) != `{"devices":{"nodes":[{"name":"C"}],"pageInfo":{"hasNextPage":false}}}` {
+		t.Fatalf("unexpected second page: %s", resp.Data)
+	}
+
+	resp = do(`{ device(id: "1") { name history(first: 5) { type state } } }`, nil)
+	if !strings.Contains(string(resp.Data), `{"type":"state_changed","state":"IN_USE"}`) {
+		t.Fatalf("expected state change in history: %s", resp.Data)
+	}
+	if resp := do(`{ device(id: "99") { name } }`, nil); string(resp.Data) != `{"device":null}` || len(resp.Errors) > 0 {
+		t.Fatalf("expected null device: %s %+v", resp.Data, resp.Errors)
+	}
+
+	// The history of a page of devices is loaded in one query.
+	var historyQueries atomic.Int32
+	_ = db.Callback().Query().After("gorm:query").Register("count_history", func(tx *gorm.DB) {
+		if !tx.DryRun && strings.Contains(tx.Statement.SQL.String(), "device_changes") {
+			historyQueries.Add(1)
+		}
+	})
+	resp = do(`{ devices(first: 3) { nodes { history(first: 2) { type } } } }`, nil)
+	if n := historyQueries.Load(); n != 1 || strings.Count(string(resp.Data), `"type"`) != 4 {
+		t.Fatalf("expected one history query for the page, got %d: %s %+v", n, resp.Data, resp.Errors)
+	}
+
+	if resp := do(`{ devices { nodes { history { id } } pageInfo { hasNextPage } } }`, nil); errCode(resp) != "query_too_complex" {
+		t.Fatalf("expected query_too_complex, got %+v", resp.Errors)
+	}
+	// Nested defaults count: history defaults to 20 items.
+	if resp := do(`{ devices(first: 2) { nodes { history { id type } } } }`, nil); errCode(resp) != "query_too_complex" {
+		t.Fatalf("expected query_too_complex for defaulted history, got %+v", resp.Errors)
+	}
+	// Root fields share one budget.
+	if resp := do(`{ a: devices(first: 10) { nodes { id name } } b: devices(first: 10) { nodes { id name } } }`, nil); errCode(resp) != "query_too_complex" {
+		t.Fatalf("expected query_too_complex across root fields, got %+v", resp.Errors)
+	}
+	// An operation over the budget is rejected as a whole: mutations ahead of
+	// the expensive one do not run either.
+	var before int64
+	db.Model(&models.Device{}).Count(&before)
+	resp = do(`mutation {
+		a: createDevice(input: {name: "D", brand: "Acme", state: AVAILABLE}) { id }
+		b: deleteDevice(id: "2")
+		c: createDevice(input: {name: "E", brand: "Acme", state: AVAILABLE}) { history(first: 30) { id type } }
+	}`, nil)
+	var after int64
+	db.Model(&models.Device{}).Count(&after)
+	if errCode(resp) != "query_too_complex" || after != before || string(resp.Data) != "" {
+		t.Fatalf("expected a rejected mutation to write nothing, got %d -> %d devices: %s %+v", before, after, resp.Data, resp.Errors)
+	}
+	if resp := do(`{ devices { nodes { name } } __schema { types { fields { type { name } } } } }`, nil); errCode(resp) != "query_too_deep" {
+		t.Fatalf("expected query_too_deep, got %+v", resp.Errors)
+	}
+	if resp := do(`{ devices { bogus } }`, nil); errCode(resp) != "validation_error" {
+		t.Fatalf("expected validation_error, got %+v", resp.Errors)
+	}
+}
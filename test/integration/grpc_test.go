@@ -0,0 +1,152 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-backend/database"
+	"go-backend/internal/grpcapi"
+	"go-backend/internal/repositories"
+	"go-backend/internal/routers"
+	"go-backend/internal/services"
+	devicesv1 "go-backend/pkg/pb/devices/v1"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+func newGRPCClient(t *testing.T, svc *services.DeviceService) devicesv1.DeviceServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv, _ := grpcapi.New(svc)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return devicesv1.NewDeviceServiceClient(conn)
+}
+
+func assertStatus(t *testing.T, err error, code codes.Code, reason string) {
+	t.Helper()
+	st, _ := status.FromError(err)
+	if st.Code() != code {
+		t.Fatalf("expected %s, got %v", code, err)
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.Reason == reason {
+			return
+		}
+	}
+	t.Fatalf("expected reason %q in %v", reason, st.Details())
+}
+
+func TestGRPC_DeviceLifecycle(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/grpc.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newGRPCClient(t, services.NewDeviceService(repositories.NewDeviceRepository(db)))
+	ctx := context.Background()
+
+	for _, name := range []string{"A", "B", "C"} {
+		if _, err := client.CreateDevice(ctx, &devicesv1.CreateDeviceRequest{Name: name, Brand: "Acme", State: devicesv1.State_STATE_AVAILABLE}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = client.CreateDevice(ctx, &devicesv1.CreateDeviceRequest{Name: "D", Brand: "Acme"})
+	assertStatus(t, err, codes.InvalidArgument, "validation_error")
+
+	page, err := client.ListDevices(ctx, &devicesv1.ListDevicesRequest{Brand: "Acme", PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Devices) != 2 || page.NextPageToken == "" {
+		t.Fatalf("unexpected first page: %v", page)
+	}
+	page, err = client.ListDevices(ctx, &devicesv1.ListDevicesRequest{Brand: "Acme", PageSize: 2, PageToken: page.NextPageToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Devices) != 1 || page.Devices[0].Name != "C" || page.NextPageToken != "" {
+		t.Fatalf("unexpected last page: %v", page)
+	}
+
+	d, err := client.PatchDevice(ctx, &devicesv1.PatchDeviceRequest{Id: 1, State: devicesv1.State_STATE_IN_USE.Enum()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.State != devicesv1.State_STATE_IN_USE || d.Name != "A" {
+		t.Fatalf("unexpected device: %v", d)
+	}
+	_, err = client.PatchDevice(ctx, &devicesv1.PatchDeviceRequest{Id: 1, Name: proto.String("Z")})
+	assertStatus(t, err, codes.FailedPrecondition, "cannot_update_name_brand_in_use")
+	_, err = client.DeleteDevice(ctx, &devicesv1.DeleteDeviceRequest{Id: 1})
+	assertStatus(t, err, codes.FailedPrecondition, "in_use_delete_blocked")
+	if _, err := client.DeleteDevice(ctx, &devicesv1.DeleteDeviceRequest{Id: 2}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.GetDevice(ctx, &devicesv1.GetDeviceRequest{Id: 2})
+	assertStatus(t, err, codes.NotFound, "not_found")
+}
+
+func TestGRPC_WatchSeesRESTChanges(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/grpc-watch.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := services.NewDeviceService(repositories.NewDeviceRepository(db))
+	client := newGRPCClient(t, svc)
+	r := newRouter(db, routers.WithDeviceService(svc))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.Watch(ctx, &devicesv1.WatchRequest{Brand: "Acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Headers arrive once the subscription is registered.
+	if _, err := stream.Header(); err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range []string{`{"name":"X","brand":"Other","state":"available"}`, `{"name":"Y","brand":"Acme","state":"available"}`} {
+		req := httptest.NewRequest(http.MethodPost, "/devices", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	ev, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	epoch, seq, _ := strings.Cut(ev.Id, "-")
+	if ev.Type != devicesv1.DeviceEvent_TYPE_CREATED || ev.Device.GetName() != "Y" || seq != "2" {
+		t.Fatalf("unexpected event: %v", ev)
+	}
+
+	resumed, err := client.Watch(ctx, &devicesv1.WatchRequest{LastEventId: epoch + "-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev, err := resumed.Recv(); err != nil || ev.Id != epoch+"-2" {
+		t.Fatalf("expected replay of event 2, got %v, %v", ev, err)
+	}
+}
@@ -2,13 +2,17 @@ package integration
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"go-backend/database"
+	"go-backend/docs"
+	"go-backend/internal/health"
 	"go-backend/internal/models"
 	"go-backend/internal/routers"
+	"io/fs"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"strings"
 	"testing"
 )
@@ -20,7 +24,7 @@ func TestHandlers_CRUD(t *testing.T) {
 		t.Fatal(err)
 	}
 	_ = db.AutoMigrate(&models.Device{})
-	r := routers.New(db)
+	r := newRouter(db)
 	req := httptest.NewRequest(http.MethodPost, "/devices", bytes.NewBufferString(`{"name":"X","brand":"Acme","state":"available"}`))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
@@ -62,7 +66,7 @@ func TestHandlers_ErrorCases(t *testing.T) {
 		t.Fatal(err)
 	}
 	_ = db.AutoMigrate(&models.Device{})
-	r := routers.New(db)
+	r := newRouter(db)
 	req := httptest.NewRequest(http.MethodGet, "/devices/abc", nil)
 	rec := httptest.NewRecorder()
 	r.ServeHTTP(rec, req)
@@ -113,13 +117,44 @@ func TestHandlers_ErrorCases(t *testing.T) {
 	}
 }
 
+func TestHandlers_MissingDeviceNotFound(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/missing.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db)
+	for _, tc := range []struct{ method, body string }{
+		{http.MethodGet, ""},
+		{http.MethodPut, `{"name":"X","brand":"Acme","state":"available","created_at":"2025-12-14T20:01:13Z"}`},
+		{http.MethodPatch, `{"state":"inactive"}`},
+		{http.MethodDelete, ""},
+	} {
+		req := httptest.NewRequest(tc.method, "/devices/42", bytes.NewBufferString(tc.body))
+		if tc.body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected 404, got %d: %s", tc.method, rec.Code, rec.Body.String())
+		}
+		var payload struct {
+			Code string `json:"code"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &payload)
+		if payload.Code != "not_found" {
+			t.Fatalf("%s: expected code not_found, got %s", tc.method, payload.Code)
+		}
+	}
+}
+
 func TestDocsAndHealthz(t *testing.T) {
 	path := t.TempDir() + "/http3.db"
 	db, err := database.Connect(path)
 	if err != nil {
 		t.Fatal(err)
 	}
-	r := routers.New(db)
+	r := newRouter(db)
 	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
 	rec := httptest.NewRecorder()
 	r.ServeHTTP(rec, req)
@@ -135,9 +170,6 @@ func TestDocsAndHealthz(t *testing.T) {
 	if !strings.Contains(rec.Body.String(), "SwaggerUIBundle") {
 		t.Fatalf("docs html missing bundle")
 	}
-	dir := t.TempDir()
-	_ = os.WriteFile(dir+"/openapi.yaml", []byte("openapi: 3.0.3"), 0644)
-	_ = os.Chdir(dir)
 	req = httptest.NewRequest(http.MethodGet, "/openapi.yaml", nil)
 	rec = httptest.NewRecorder()
 	r.ServeHTTP(rec, req)
@@ -147,4 +179,96 @@ func TestDocsAndHealthz(t *testing.T) {
 	if !strings.Contains(rec.Body.String(), "openapi:") {
 		t.Fatalf("openapi yaml missing header")
 	}
+	req = httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	var spec map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil || spec["openapi"] == nil {
+		t.Fatalf("invalid openapi json: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/docs/assets/swagger-ui/swagger-ui-bundle.js", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" || rec.Header().Get("Cache-Control") == "" {
+		t.Fatalf("expected cacheable asset, got %d %v", rec.Code, rec.Header())
+	}
+	req = httptest.NewRequest(http.MethodGet, "/docs/assets/swagger-ui/swagger-ui-bundle.js", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", rec.Code)
+	}
+}
+
+func TestHealthz_NotReady(t *testing.T) {
+	path := t.TempDir() + "/http4.db"
+	db, err := database.Connect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := health.NewStatus()
+	r := newRouter(db, routers.WithHealth(status))
+	status.SetReady(false)
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestReadyz_Checks(t *testing.T) {
+	path := t.TempDir() + "/http5.db"
+	db, err := database.Connect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := health.NewStatus()
+	r := newRouter(db, routers.WithHealth(status))
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var rep health.Report
+	_ = json.Unmarshal(rec.Body.Bytes(), &rep)
+	if rep.Checks["database"].Status != health.StatusOK || rep.Checks["migrations"].Status != health.StatusOK {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	status.Register("disk", func(ctx context.Context) error { return errors.New("disk full") })
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &rep)
+	if rep.Checks["disk"].Error != "disk full" {
+		t.Fatalf("unexpected disk check: %+v", rep.Checks["disk"])
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+}
+
+func TestDocs_ReDoc(t *testing.T) {
+	if _, err := fs.Stat(docs.UI, "ui/redoc/redoc.standalone.js"); err != nil {
+		t.Skip("ReDoc bundle is not vendored under docs/ui/redoc")
+	}
+	db, err := database.Connect(t.TempDir() + "/redoc.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db)
+	for _, path := range []string{"/docs/redoc", "/docs/assets/redoc/redoc.standalone.js"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, rec.Code)
+		}
+	}
 }
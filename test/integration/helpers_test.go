@@ -0,0 +1,31 @@
+package integration
+
+import (
+	"bytes"
+	"go-backend/internal/routers"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// newRouter builds the router with request and response validation against
+// the generated OpenAPI document switched on.
+func newRouter(db *gorm.DB, opts ...routers.Option) *gin.Engine {
+	return routers.New(db, append([]routers.Option{routers.WithSpecValidation(true, true)}, opts...)...)
+}
+
+// requester returns a function that serves a request through r, sending a
+// non-empty body as JSON.
+func requester(r http.Handler) func(method, path, body string) *httptest.ResponseRecorder {
+	return func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+}
@@ -0,0 +1,146 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-backend/database"
+	"go-backend/internal/dto"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	apperror "go-backend/pkg/error"
+
+	"gorm.io/gorm"
+)
+
+func TestIdentifiers_UniqueSerialAndAssetTag(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/identifiers.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db)
+	do := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	conflict := func(rec *httptest.ResponseRecorder, field string, existing int64) {
+		t.Helper()
+		var p struct {
+			apperror.ErrorPayload
+			Details dto.DuplicateDetails `json:"details"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &p)
+		if rec.Code != http.StatusConflict || p.Code != "duplicate_device" || p.Details.Field != field || p.Details.ExistingID != existing {
+			t.Fatalf("expected duplicate %s of %d: %d %s", field, existing, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := do(http.MethodPost, "/v1/devices", "application/json",
+		`{"name":"a","brand":"Acme","state":"available","serial_number":" SN-1 ","asset_tag":"IT-0001"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", rec.Code, rec.Body.String())
+	}
+	var a dto.DeviceResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &a)
+	if a.SerialNumber == nil || *a.SerialNumber != "SN-1" {
+		t.Fatalf("serial not trimmed: %v", a.SerialNumber)
+	}
+	conflict(do(http.MethodPost, "/v1/devices", "application/json",
+		`{"name":"b","brand":"Acme","state":"available","serial_number":"SN-1"}`), "serial_number", a.ID)
+	conflict(do(http.MethodPost, "/v1/devices", "application/json",
+		`{"name":"b","brand":"Acme","state":"available","asset_tag":"IT-0001"}`), "asset_tag", a.ID)
+
+	// Devices without identifiers do not collide.
+	var b dto.DeviceResponse
+	for range 2 {
+		rec = do(http.MethodPost, "/v1/devices", "application/json", `{"name":"b","brand":"Acme","state":"available","serial_number":""}`)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create without identifiers: %d %s", rec.Code, rec.Body.String())
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &b)
+	}
+	bPath := "/v1/devices/" + strconv.FormatInt(b.ID, 10)
+	conflict(do(http.MethodPatch, bPath, "application/json", `{"serial_number":"SN-1"}`), "serial_number", a.ID)
+	conflict(do(http.MethodPatch, bPath, "application/merge-patch+json", `{"asset_tag":"IT-0001"}`), "asset_tag", a.ID)
+	conflict(do(http.MethodPut, bPath, "application/json",
+		`{"name":"b","brand":"Acme","state":"available","serial_number":"SN-1"}`), "serial_number", a.ID)
+	if rec := do(http.MethodPatch, bPath, "application/json", `{"serial_number":"SN-2"}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("patch: %d %s", rec.Code, rec.Body.String())
+	}
+
+	var found dto.DeviceResponse
+	rec = do(http.MethodGet, "/v1/devices/by-serial/SN-2", "", "")
+	_ = json.Unmarshal(rec.Body.Bytes(), &found)
+	if rec.Code != http.StatusOK || found.ID != b.ID {
+		t.Fatalf("by serial: %d %s", rec.Code, rec.Body.String())
+	}
+	rec = do(http.MethodGet, "/v1/devices/by-asset-tag/IT-0001", "", "")
+	_ = json.Unmarshal(rec.Body.Bytes(), &found)
+	if rec.Code != http.StatusOK || found.ID != a.ID {
+		t.Fatalf("by asset tag: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodGet, "/v1/devices/by-serial/SN-9", "", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown serial: %d %s", rec.Code, rec.Body.String())
+	}
+
+	// Removing a serial number frees it for another device.
+	if rec := do(http.MethodPatch, bPath, "application/merge-patch+json", `{"serial_number":null}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("remove serial: %d %s", rec.Code, rec.Body.String())
+	}
+	aPath := "/v1/devices/" + strconv.FormatInt(a.ID, 10)
+	if rec := do(http.MethodPatch, aPath, "application/json", `{"serial_number":"SN-2"}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("reuse serial: %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestIdentifiers_ConcurrentWriteHitsIndex(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/identifiers-race.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Hide the owner from the next pre-check, as if it had been written by a
+	// concurrent request after the check ran, so the unique index rejects
+	// the write.
+	var race atomic.Bool
+	_ = db.Callback().Query().After("gorm:query").Register("lose_race", func(tx *gorm.DB) {
+		if !tx.DryRun && strings.Contains(tx.Statement.SQL.String(), "serial_number = ") && race.CompareAndSwap(true, false) {
+			tx.Error = gorm.ErrRecordNotFound
+		}
+	})
+	r := newRouter(db)
+	do := requester(r)
+	conflict := func(rec *httptest.ResponseRecorder, existing int64) {
+		t.Helper()
+		var p struct {
+			apperror.ErrorPayload
+			Details dto.DuplicateDetails `json:"details"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &p)
+		if rec.Code != http.StatusConflict || p.Code != "duplicate_device" || p.Details.Field != "serial_number" || p.Details.ExistingID != existing {
+			t.Fatalf("expected duplicate serial_number of %d: %d %s", existing, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := do(http.MethodPost, "/v1/devices", `{"name":"a","brand":"Acme","state":"available","serial_number":"SN-1"}`)
+	var a dto.DeviceResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &a)
+	race.Store(true)
+	conflict(do(http.MethodPost, "/v1/devices", `{"name":"b","brand":"Acme","state":"available","serial_number":"SN-1"}`), a.ID)
+
+	rec = do(http.MethodPost, "/v1/devices", `{"name":"c","brand":"Acme","state":"available"}`)
+	var c dto.DeviceResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &c)
+	race.Store(true)
+	conflict(do(http.MethodPatch, "/v1/devices/"+strconv.FormatInt(c.ID, 10), `{"serial_number":"SN-1"}`), a.ID)
+	if race.Load() {
+		t.Fatal("pre-check did not run")
+	}
+}
@@ -0,0 +1,87 @@
+package integration
+
+import (
+	"bytes"
+	"go-backend/config"
+	"go-backend/database"
+	"go-backend/internal/routers"
+	"image/png"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestLabels_DeviceAndSheet(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/labels.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db, routers.WithLabels(config.LabelsConfig{DeviceURL: "https://lab.example/devices/{id}", MaxBatch: 2}))
+	do := requester(r)
+	for _, body := range []string{
+		`{"name":"Pixel <8>","brand":"Google","state":"available"}`,
+		`{"name":"iPhone","brand":"Apple","state":"available"}`,
+		`{"name":"Galaxy","brand":"Samsung","state":"in-use"}`,
+	} {
+		if rec := do(http.MethodPost, "/v1/devices", body); rec.Code != http.StatusCreated {
+			t.Fatalf("create: %d %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := do(http.MethodGet, "/v1/devices/1/label", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("png: %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	img, err := png.Decode(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() <= b.Dy() {
+		t.Fatalf("unexpected label size %v", b)
+	}
+
+	rec = do(http.MethodGet, "/v1/devices/1/label?format=svg", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/svg+xml" {
+		t.Fatalf("svg: %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	for _, want := range []string{"<svg", "Pixel &lt;8&gt;", "Google", "ID 1"} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("svg lacks %q: %s", want, rec.Body.String())
+		}
+	}
+	if rec := do(http.MethodGet, "/v1/devices/1/label?format=gif", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("bad format: %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/v1/devices/99/label", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("missing device: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = do(http.MethodGet, "/v1/devices/labels?state=available", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/pdf" {
+		t.Fatalf("pdf: %d %s", rec.Code, rec.Body.String())
+	}
+	if !bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF-")) {
+		t.Fatalf("not a PDF: %q", rec.Body.Bytes()[:16])
+	}
+	for range 5 {
+		if rec := do(http.MethodPost, "/v1/devices", `{"name":"Spare","brand":"Acme","state":"available"}`); rec.Code != http.StatusCreated {
+			t.Fatalf("create: %d %s", rec.Code, rec.Body.String())
+		}
+	}
+	// An oversized sheet reads one device past max_batch, not every match.
+	var rows atomic.Int64
+	_ = db.Callback().Query().After("gorm:query").Register("count_label_rows", func(tx *gorm.DB) {
+		if !tx.DryRun && tx.Statement.Table == "devices" {
+			rows.Add(tx.Statement.RowsAffected)
+		}
+	})
+	if rec := do(http.MethodGet, "/v1/devices/labels", ""); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("over max_batch: %d %s", rec.Code, rec.Body.String())
+	}
+	if n := rows.Load(); n != 3 {
+		t.Fatalf("expected 3 device rows read, got %d", n)
+	}
+}
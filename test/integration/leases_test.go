@@ -0,0 +1,199 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"go-backend/config"
+	"go-backend/database"
+	"go-backend/internal/dto"
+	"go-backend/internal/models"
+	"go-backend/internal/repositories"
+	"go-backend/internal/routers"
+	"go-backend/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLeases_RenewReleaseAndReap(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/leases.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.LeasesConfig{DefaultTTL: time.Hour, MaxTTL: 24 * time.Hour, ReapInterval: time.Minute}
+	r := newRouter(db, routers.WithLeases(cfg))
+	do := requester(r)
+	rec := do(http.MethodPost, "/v1/devices", `{"name":"scope","brand":"Acme","state":"available"}`)
+	var d dto.DeviceResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &d)
+	device := "/v1/devices/" + strconv.FormatInt(d.ID, 10)
+	state := func() string {
+		t.Helper()
+		var d dto.DeviceResponse
+		_ = json.Unmarshal(do(http.MethodGet, device, "").Body.Bytes(), &d)
+		return d.State
+	}
+
+	if rec := do(http.MethodPost, device+"/lease", `{"holder":"qa","ttl_seconds":172800}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("ttl above max: %d %s", rec.Code, rec.Body.String())
+	}
+	rec = do(http.MethodPost, device+"/lease", `{"holder":"qa"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("acquire: %d %s", rec.Code, rec.Body.String())
+	}
+	var l dto.LeaseResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &l)
+	if l.Status != "active" || l.ExpiresAt.Sub(l.CreatedAt) != time.Hour || state() != "in-use" {
+		t.Fatalf("unexpected lease: %+v", l)
+	}
+	if rec := do(http.MethodPost, device+"/lease", `{"holder":"other"}`); rec.Code != http.StatusConflict {
+		t.Fatalf("second lease: %d %s", rec.Code, rec.Body.String())
+	}
+	lease := "/v1/leases/" + strconv.FormatInt(l.ID, 10)
+	rec = do(http.MethodPost, lease+"/renew", `{"ttl_seconds":7200}`)
+	var renewed dto.LeaseResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &renewed)
+	if rec.Code != http.StatusOK || !renewed.ExpiresAt.After(l.ExpiresAt) {
+		t.Fatalf("renew: %d %s", rec.Code, rec.Body.String())
+	}
+	rec = do(http.MethodPost, lease+"/release", "")
+	if rec.Code != http.StatusOK || state() != "available" {
+		t.Fatalf("release: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, lease+"/release", ""); rec.Code != http.StatusConflict {
+		t.Fatalf("release twice: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodGet, device+"/lease", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("no active lease: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = do(http.MethodPost, device+"/lease", `{"holder":"qa","ttl_seconds":60}`)
+	_ = json.Unmarshal(rec.Body.Bytes(), &l)
+	ctx := context.Background()
+	leases := services.NewLeaseService(repositories.NewLeaseRepository(db),
+		services.NewDeviceService(repositories.NewDeviceRepository(db)), cfg)
+	if n, err := leases.Reap(ctx, l.ExpiresAt.Add(-time.Second)); err != nil || n != 0 {
+		t.Fatalf("reap before expiry: %d %v", n, err)
+	}
+	if n, err := leases.Reap(ctx, l.ExpiresAt); err != nil || n != 1 {
+		t.Fatalf("reap: %d %v", n, err)
+	}
+	// A second instance reaping the same moment finds nothing left to claim.
+	if n, err := leases.Reap(ctx, l.ExpiresAt); err != nil || n != 0 {
+		t.Fatalf("reap twice: %d %v", n, err)
+	}
+	if s := state(); s != "available" {
+		t.Fatalf("state after expiry: %s", s)
+	}
+	_ = json.Unmarshal(do(http.MethodGet, "/v1/leases/"+strconv.FormatInt(l.ID, 10), "").Body.Bytes(), &l)
+	if l.Status != "expired" || l.EndedAt == nil {
+		t.Fatalf("expired lease: %+v", l)
+	}
+	var audit int64
+	db.Model(&models.OutboxEvent{}).Where("event_type = ? AND device_id = ?", "lease_expired", d.ID).Count(&audit)
+	if audit != 1 {
+		t.Fatalf("lease_expired events: %d", audit)
+	}
+
+	// Checking a leased device in by other means ends its lease, so it cannot
+	// expire under a later checkout.
+	rec = do(http.MethodPost, device+"/lease", `{"holder":"qa","ttl_seconds":60}`)
+	_ = json.Unmarshal(rec.Body.Bytes(), &l)
+	if rec := do(http.MethodPatch, device, `{"state":"available"}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("patch: %d %s", rec.Code, rec.Body.String())
+	}
+	_ = json.Unmarshal(do(http.MethodGet, "/v1/leases/"+strconv.FormatInt(l.ID, 10), "").Body.Bytes(), &l)
+	if l.Status != "released" {
+		t.Fatalf("lease after checkin: %+v", l)
+	}
+}
+
+func TestLeases_ReservationsTakePrecedence(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/lease-reservations.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.LeasesConfig{DefaultTTL: time.Hour, MaxTTL: 24 * time.Hour, ReapInterval: time.Minute}
+	r := newRouter(db, routers.WithLeases(cfg))
+	do := requester(r)
+	rec := do(http.MethodPost, "/v1/devices", `{"name":"scope","brand":"Acme","state":"available"}`)
+	var d dto.DeviceResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &d)
+	device := "/v1/devices/" + strconv.FormatInt(d.ID, 10)
+	start := time.Now().UTC().Add(30 * time.Minute).Truncate(time.Second)
+	body, _ := json.Marshal(dto.CreateReservationRequest{Holder: "lab", StartsAt: start, EndsAt: start.Add(time.Hour)})
+	rec = do(http.MethodPost, device+"/reservations", string(body))
+	var res dto.ReservationResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &res)
+	conflict := func(rec *httptest.ResponseRecorder) {
+		t.Helper()
+		var payload struct {
+			Code    string                         `json:"code"`
+			Details dto.ReservationConflictDetails `json:"details"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &payload)
+		if rec.Code != http.StatusConflict || payload.Code != "reservation_conflict" || payload.Details.ReservationID != res.ID {
+			t.Fatalf("expected reservation_conflict: %d %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	// A lease may not run into the booked window, nor be renewed into it.
+	conflict(do(http.MethodPost, device+"/lease", `{"holder":"qa","ttl_seconds":3600}`))
+	var state dto.DeviceResponse
+	_ = json.Unmarshal(do(http.MethodGet, device, "").Body.Bytes(), &state)
+	if state.State != "available" {
+		t.Fatalf("refused lease changed the device: %s", state.State)
+	}
+	rec = do(http.MethodPost, device+"/lease", `{"holder":"qa","ttl_seconds":600}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("short lease: %d %s", rec.Code, rec.Body.String())
+	}
+	var l dto.LeaseResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &l)
+	lease := "/v1/leases/" + strconv.FormatInt(l.ID, 10)
+	conflict(do(http.MethodPost, lease+"/renew", `{"ttl_seconds":3600}`))
+	// Nor may a booking start before the active lease expires.
+	early := time.Now().UTC().Add(5 * time.Minute).Truncate(time.Second)
+	body, _ = json.Marshal(dto.CreateReservationRequest{Holder: "lab", StartsAt: early, EndsAt: early.Add(10 * time.Minute)})
+	rec = do(http.MethodPost, device+"/reservations", string(body))
+	var payload struct {
+		Code string `json:"code"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &payload)
+	if rec.Code != http.StatusConflict || payload.Code != "device_not_available" {
+		t.Fatalf("booking into a lease: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, lease+"/release", ""); rec.Code != http.StatusOK {
+		t.Fatalf("release: %d %s", rec.Code, rec.Body.String())
+	}
+
+	// The reservation checks the device out; someone returns it and it is
+	// leased again before the reservation ends.
+	ctx := context.Background()
+	devices := services.NewDeviceService(repositories.NewDeviceRepository(db))
+	reservations := services.NewReservationService(repositories.NewReservationRepository(db), devices)
+	if err := reservations.Process(ctx, start); err != nil {
+		t.Fatal(err)
+	}
+	if rec := do(http.MethodPatch, device, `{"state":"available"}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("return: %d %s", rec.Code, rec.Body.String())
+	}
+	other := models.Lease{DeviceID: d.ID, Holder: "other", Status: models.LeaseActive, ExpiresAt: start.Add(2 * time.Hour), CreatedAt: start}
+	if err := db.Create(&other).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&models.Device{}).Where("id = ?", d.ID).Update("state", models.StateInUse).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := reservations.Process(ctx, start.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	_ = json.Unmarshal(do(http.MethodGet, device, "").Body.Bytes(), &state)
+	var kept models.Lease
+	db.First(&kept, other.ID)
+	if state.State != "in-use" || kept.Status != models.LeaseActive {
+		t.Fatalf("ending reservation took the device from a lease: %s %s", state.State, kept.Status)
+	}
+}
@@ -0,0 +1,78 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-backend/database"
+	"go-backend/internal/openapi"
+	"go-backend/internal/routers"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestOpenAPI_SpecInSync(t *testing.T) {
+	want, err := yaml.Marshal(routers.Spec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("../../docs/swagger/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("docs/swagger/openapi.yaml is out of date, run `make openapi`")
+	}
+}
+
+func TestOpenAPI_RequestValidation(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/openapi.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db)
+	cases := []struct {
+		method, path, body string
+	}{
+		{http.MethodPost, "/devices", `{"name":"X","brand":"Acme","state":"available","id":5}`},
+		{http.MethodPost, "/devices", `{"name":"","brand":"Acme","state":"available"}`},
+		{http.MethodGet, "/devices?state=broken", ""},
+		{http.MethodPut, "/devices/1", `{"name":"X","brand":"Acme","state":"available","created_at":true}`},
+		{http.MethodPatch, "/devices/1", `{"state":7}`},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s %s: expected 400, got %d: %s", tc.method, tc.path, rec.Code, rec.Body.String())
+		}
+		var payload struct {
+			Code    string   `json:"code"`
+			Details []string `json:"details"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &payload)
+		if payload.Code != "validation_error" || len(payload.Details) == 0 {
+			t.Fatalf("%s %s: unexpected payload %s", tc.method, tc.path, rec.Body.String())
+		}
+	}
+}
+
+func TestOpenAPI_OneOfRequiresExactlyOneMatch(t *testing.T) {
+	one := 1
+	doc := &openapi.Document{}
+	s := &openapi.Schema{OneOf: []*openapi.Schema{{Type: "string"}, {Type: "string", MinLength: &one}}}
+	if errs := doc.ValidateJSON(s, []byte(`""`)); len(errs) != 0 {
+		t.Fatalf("expected one match to pass, got %v", errs)
+	}
+	if errs := doc.ValidateJSON(s, []byte(`"abc"`)); len(errs) != 1 {
+		t.Fatalf("expected two matches to fail, got %v", errs)
+	}
+	if errs := doc.ValidateJSON(s, []byte(`7`)); len(errs) != 1 {
+		t.Fatalf("expected no match to fail, got %v", errs)
+	}
+}
@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-backend/database"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPatch_MergeAndJSONPatch(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/patch.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db)
+	do := func(method, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/devices/1", bytes.NewBufferString(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	req := httptest.NewRequest(http.MethodPost, "/devices", bytes.NewBufferString(`{"name":"X","brand":"Acme","state":"available"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	const merge, jsonPatch = "application/merge-patch+json", "application/json-patch+json"
+	cases := []struct {
+		name, contentType, body string
+		status                  int
+		code                    string
+	}{
+		{"merge sets state", merge, `{"state":"in-use"}`, http.StatusNoContent, ""},
+		{"merge blocked while in use", merge, `{"name":"Z"}`, http.StatusUnprocessableEntity, "cannot_update_name_brand_in_use"},
+		{"merge removes required field", merge, `{"brand":null}`, http.StatusUnprocessableEntity, "invalid_patch"},
+		{"failed test", jsonPatch, `[{"op":"test","path":"/state","value":"available"},{"op":"replace","path":"/state","value":"inactive"}]`, http.StatusConflict, "patch_test_failed"},
+		{"passing test", jsonPatch, `[{"op":"test","path":"/state","value":"in-use"},{"op":"replace","path":"/state","value":"available"}]`, http.StatusNoContent, ""},
+		{"created_at", jsonPatch, `[{"op":"replace","path":"/created_at","value":"01.01.2020 00:00:00"}]`, http.StatusUnprocessableEntity, "cannot_update_created_at"},
+		{"id", jsonPatch, `[{"op":"replace","path":"/id","value":2}]`, http.StatusUnprocessableEntity, "invalid_patch"},
+		{"missing path", jsonPatch, `[{"op":"remove","path":"/nope"}]`, http.StatusUnprocessableEntity, "invalid_patch"},
+		{"unsupported type", "text/plain", `state=inactive`, http.StatusUnsupportedMediaType, "unsupported_media_type"},
+	}
+	for _, tc := range cases {
+		rec := do(http.MethodPatch, tc.contentType, tc.body)
+		if rec.Code != tc.status {
+			t.Fatalf("%s: expected %d, got %d: %s", tc.name, tc.status, rec.Code, rec.Body.String())
+		}
+		if tc.code != "" {
+			var p struct{ Code string }
+			_ = json.Unmarshal(rec.Body.Bytes(), &p)
+			if p.Code != tc.code {
+				t.Fatalf("%s: expected code %q, got %s", tc.name, tc.code, rec.Body.String())
+			}
+		}
+	}
+	rec := do(http.MethodGet, "", "")
+	var d struct{ Name, State string }
+	_ = json.Unmarshal(rec.Body.Bytes(), &d)
+	if d.Name != "X" || d.State != "available" {
+		t.Fatalf("unexpected device after patches: %s", rec.Body.String())
+	}
+	// Only the patch route answers 415; elsewhere an unknown content type is
+	// still a validation error.
+	if rec := do(http.MethodPut, "text/plain", `name=Y`); rec.Code != http.StatusBadRequest || !bytes.Contains(rec.Body.Bytes(), []byte("validation_error")) {
+		t.Fatalf("expected 400 validation_error, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,143 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"go-backend/database"
+	"go-backend/internal/dto"
+	"go-backend/internal/repositories"
+	"go-backend/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestReservations_BookingAndScheduling(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/reservations.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db)
+	do := requester(r)
+	create := func(name, brand string) int64 {
+		t.Helper()
+		rec := do(http.MethodPost, "/v1/devices", `{"name":"`+name+`","brand":"`+brand+`","state":"available"}`)
+		var d dto.DeviceResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &d)
+		return d.ID
+	}
+	reserve := func(id int64, from, to time.Time) *httptest.ResponseRecorder {
+		t.Helper()
+		body, _ := json.Marshal(dto.CreateReservationRequest{Holder: "qa", StartsAt: from, EndsAt: to})
+		return do(http.MethodPost, "/v1/devices/"+strconv.FormatInt(id, 10)+"/reservations", string(body))
+	}
+	state := func(id int64) string {
+		t.Helper()
+		var d dto.DeviceResponse
+		_ = json.Unmarshal(do(http.MethodGet, "/v1/devices/"+strconv.FormatInt(id, 10), "").Body.Bytes(), &d)
+		return d.State
+	}
+	available := func(from, to time.Time, brand string) []int64 {
+		t.Helper()
+		q := url.Values{"from": {from.Format(time.RFC3339)}, "to": {to.Format(time.RFC3339)}}
+		if brand != "" {
+			q.Set("brand", brand)
+		}
+		rec := do(http.MethodGet, "/v1/devices/available?"+q.Encode(), "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("available: %d %s", rec.Code, rec.Body.String())
+		}
+		var list []dto.DeviceResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &list)
+		var ids []int64
+		for _, d := range list {
+			ids = append(ids, d.ID)
+		}
+		return ids
+	}
+
+	a, b := create("a", "Acme"), create("b", "Other")
+	start := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+
+	rec := reserve(a, start, end)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("reserve: %d %s", rec.Code, rec.Body.String())
+	}
+	var res dto.ReservationResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &res)
+	if res.Status != "scheduled" {
+		t.Fatalf("unexpected reservation: %+v", res)
+	}
+	rec = reserve(a, start.Add(time.Hour), end.Add(time.Hour))
+	var conflict struct {
+		Code    string                         `json:"code"`
+		Details dto.ReservationConflictDetails `json:"details"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &conflict)
+	if rec.Code != http.StatusConflict || conflict.Details.ReservationID != res.ID {
+		t.Fatalf("overlap: %d %s", rec.Code, rec.Body.String())
+	}
+	// Back-to-back windows do not overlap.
+	if rec := reserve(a, end, end.Add(time.Hour)); rec.Code != http.StatusCreated {
+		t.Fatalf("adjacent: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := reserve(a, end, start); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("inverted window: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := reserve(99, start, end); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown device: %d %s", rec.Code, rec.Body.String())
+	}
+
+	if got := available(start, end, ""); len(got) != 1 || got[0] != b {
+		t.Fatalf("available during the reservation: %v", got)
+	}
+	if got := available(start.Add(-time.Minute), start, "acme"); len(got) != 1 || got[0] != a {
+		t.Fatalf("available before the reservation: %v", got)
+	}
+
+	var list []dto.ReservationResponse
+	_ = json.Unmarshal(do(http.MethodGet, "/v1/devices/"+strconv.FormatInt(a, 10)+"/reservations", "").Body.Bytes(), &list)
+	if len(list) != 2 || list[0].ID != res.ID {
+		t.Fatalf("device reservations: %+v", list)
+	}
+
+	ctx := context.Background()
+	reservations := services.NewReservationService(repositories.NewReservationRepository(db),
+		services.NewDeviceService(repositories.NewDeviceRepository(db)))
+	if err := reservations.Process(ctx, start); err != nil {
+		t.Fatal(err)
+	}
+	if s := state(a); s != "in-use" {
+		t.Fatalf("state after start: %s", s)
+	}
+	// A second instance processing the same moment changes nothing.
+	if err := reservations.Process(ctx, start); err != nil {
+		t.Fatal(err)
+	}
+	// The first reservation hands the device over to the adjacent one.
+	if err := reservations.Process(ctx, end); err != nil {
+		t.Fatal(err)
+	}
+	if s := state(a); s != "in-use" {
+		t.Fatalf("state at hand-over: %s", s)
+	}
+	_ = json.Unmarshal(do(http.MethodGet, "/v1/reservations/"+strconv.FormatInt(res.ID, 10), "").Body.Bytes(), &res)
+	if res.Status != "completed" {
+		t.Fatalf("first reservation: %+v", res)
+	}
+
+	rec = do(http.MethodPost, "/v1/reservations/"+strconv.FormatInt(list[1].ID, 10)+"/cancel", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("cancel active: %d %s", rec.Code, rec.Body.String())
+	}
+	if s := state(a); s != "available" {
+		t.Fatalf("state after ending early: %s", s)
+	}
+	if rec := do(http.MethodPost, "/v1/reservations/"+strconv.FormatInt(list[1].ID, 10)+"/cancel", ""); rec.Code != http.StatusConflict {
+		t.Fatalf("cancel twice: %d %s", rec.Code, rec.Body.String())
+	}
+}
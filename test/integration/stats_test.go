@@ -0,0 +1,93 @@
+package integration
+
+import (
+	"encoding/json"
+	"go-backend/database"
+	"go-backend/internal/dto"
+	"go-backend/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStats_GroupByAndBuckets(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/stats.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(y int, m time.Month, d int) models.FormattedTime {
+		return models.NewFormattedTime(time.Date(y, m, d, 10, 0, 0, 0, time.UTC))
+	}
+	for _, d := range []models.Device{
+		{Name: "a", Brand: "Acme", State: models.StateAvailable, CreatedAt: day(2025, 12, 1)},   // Monday
+		{Name: "b", Brand: "Acme", State: models.StateInUse, CreatedAt: day(2025, 12, 7)},       // Sunday, same week
+		{Name: "c", Brand: "Globex", State: models.StateAvailable, CreatedAt: day(2025, 12, 8)}, // next Monday
+		{Name: "d", Brand: "Acme", State: models.StateAvailable, CreatedAt: day(2026, 1, 15)},
+	} {
+		if err := db.Create(&d).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := newRouter(db)
+	stats := func(query string) dto.DeviceStatsResponse {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/devices/stats?"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: %d %s", query, rec.Code, rec.Body.String())
+		}
+		var res dto.DeviceStatsResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	type bucket struct {
+		key   string
+		count int64
+	}
+	flatten := func(res dto.DeviceStatsResponse) []bucket {
+		var out []bucket
+		for _, b := range res.Buckets {
+			key := ""
+			for _, p := range []*string{b.Brand, b.State, b.Period} {
+				if p != nil {
+					key += *p + "|"
+				}
+			}
+			out = append(out, bucket{key, b.Count})
+		}
+		return out
+	}
+	expect := func(query string, want ...bucket) {
+		t.Helper()
+		got := flatten(stats(query))
+		if len(got) != len(want) {
+			t.Fatalf("%s: got %v, want %v", query, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s: got %v, want %v", query, got, want)
+			}
+		}
+	}
+
+	if res := stats(""); res.Total != 4 || len(res.Buckets) != 1 {
+		t.Fatalf("unexpected ungrouped stats %+v", res)
+	}
+	expect("group_by=brand,state",
+		bucket{"Acme|available|", 2}, bucket{"Acme|in-use|", 1}, bucket{"Globex|available|", 1})
+	expect("interval=month", bucket{"2025-12|", 3}, bucket{"2026-01|", 1})
+	expect("interval=week", bucket{"2025-12-01|", 2}, bucket{"2025-12-08|", 1}, bucket{"2026-01-12|", 1})
+	expect("group_by=brand&interval=day&state=available&from=2025-12-01&to=2025-12-31",
+		bucket{"Acme|2025-12-01|", 1}, bucket{"Globex|2025-12-08|", 1})
+
+	for _, q := range []string{"group_by=name", "interval=year", "from=01.12.2025"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/devices/stats?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", q, rec.Code)
+		}
+	}
+}
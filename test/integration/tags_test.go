@@ -0,0 +1,93 @@
+package integration
+
+import (
+	"encoding/json"
+	"go-backend/database"
+	"go-backend/internal/dto"
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestTags_TagFilterAndCounts(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/tags.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db)
+	do := requester(r)
+	create := func(name string) string {
+		t.Helper()
+		rec := do(http.MethodPost, "/v1/devices", `{"name":"`+name+`","brand":"Acme","state":"available"}`)
+		var d dto.DeviceResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &d)
+		return "/v1/devices/" + strconv.FormatInt(d.ID, 10)
+	}
+	names := func(path string) []string {
+		t.Helper()
+		rec := do(http.MethodGet, path, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: %d %s", path, rec.Code, rec.Body.String())
+		}
+		var list []dto.DeviceResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &list)
+		var out []string
+		for _, d := range list {
+			out = append(out, d.Name)
+		}
+		return out
+	}
+	a, b := create("a"), create("b")
+	create("c")
+	for _, tc := range []struct{ path, tag string }{
+		{a, "Test%20Lab"}, {a, "team-a"}, {b, "test-lab"}, {b, "test-lab"},
+	} {
+		if rec := do(http.MethodPut, tc.path+"/tags/"+tc.tag, ""); rec.Code != http.StatusNoContent {
+			t.Fatalf("tag %s %s: %d %s", tc.path, tc.tag, rec.Code, rec.Body.String())
+		}
+	}
+
+	var tags dto.DeviceTagsResponse
+	_ = json.Unmarshal(do(http.MethodGet, a+"/tags", "").Body.Bytes(), &tags)
+	if !slices.Equal(tags.Tags, []string{"team-a", "test-lab"}) {
+		t.Fatalf("unexpected tags of a: %v", tags.Tags)
+	}
+	if got := names("/v1/devices?tag=test-lab,team-a"); !slices.Equal(got, []string{"a", "b"}) {
+		t.Fatalf("any: %v", got)
+	}
+	if got := names("/v1/devices?tag=test-lab,team-a&tag_match=all"); !slices.Equal(got, []string{"a"}) {
+		t.Fatalf("all: %v", got)
+	}
+	if got := names("/v1/devices?tag=TEAM-A"); !slices.Equal(got, []string{"a"}) {
+		t.Fatalf("normalized filter: %v", got)
+	}
+
+	var counts []dto.TagResponse
+	_ = json.Unmarshal(do(http.MethodGet, "/v1/tags", "").Body.Bytes(), &counts)
+	if want := []dto.TagResponse{{Name: "team-a", Devices: 1}, {Name: "test-lab", Devices: 2}}; !slices.Equal(counts, want) {
+		t.Fatalf("counts: %+v", counts)
+	}
+
+	if rec := do(http.MethodDelete, a+"/tags/team-a", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("untag: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodDelete, b, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: %d %s", rec.Code, rec.Body.String())
+	}
+	counts = nil
+	_ = json.Unmarshal(do(http.MethodGet, "/v1/tags", "").Body.Bytes(), &counts)
+	if want := []dto.TagResponse{{Name: "test-lab", Devices: 1}}; !slices.Equal(counts, want) {
+		t.Fatalf("counts after removal: %+v", counts)
+	}
+
+	if rec := do(http.MethodPut, a+"/tags/bad%3Ftag", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid tag: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPut, "/v1/devices/999/tags/lab", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("missing device: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodGet, "/v1/devices?tag_match=some", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid tag_match: %d", rec.Code)
+	}
+}
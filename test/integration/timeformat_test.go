@@ -0,0 +1,87 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-backend/database"
+	"go-backend/internal/models"
+	"go-backend/internal/routers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeFormat_SelectionAndInput(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/timeformat.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db)
+	do := func(method, target, header, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		if header != "" {
+			req.Header.Set("X-Time-Format", header)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	createdAt := func(rec *httptest.ResponseRecorder) any {
+		var d struct {
+			CreatedAt any `json:"created_at"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &d); err != nil {
+			t.Fatalf("decode %s: %v", rec.Body.String(), err)
+		}
+		return d.CreatedAt
+	}
+
+	rec := do(http.MethodPost, "/v1/devices?time_format=rfc3339", "", "application/json", `{"name":"X","brand":"Acme","state":"available"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", rec.Code, rec.Body.String())
+	}
+	s, _ := createdAt(rec).(string)
+	created, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatalf("expected RFC 3339 created_at, got %s", rec.Body.String())
+	}
+
+	if got, _ := createdAt(do(http.MethodGet, "/v1/devices/1", "", "", "")).(string); got != created.Format(models.DbTimeLayout) {
+		t.Fatalf("expected legacy default, got %q", got)
+	}
+	if got, _ := createdAt(do(http.MethodGet, "/v1/devices/1", "epoch", "", "")).(float64); int64(got) != created.Unix() {
+		t.Fatalf("expected epoch from header, got %v", got)
+	}
+	if _, ok := createdAt(do(http.MethodGet, "/v1/devices/1?time_format=rfc3339", "epoch", "", "")).(string); !ok {
+		t.Fatalf("query parameter must win over the header")
+	}
+	if rec := do(http.MethodGet, "/v1/devices?time_format=iso", "", "", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown format, got %d", rec.Code)
+	}
+
+	for _, in := range []string{
+		fmt.Sprintf("%q", created.Format(time.RFC3339)),
+		fmt.Sprintf("%q", created.Format(models.DbTimeLayout)),
+		fmt.Sprint(created.Unix()),
+	} {
+		rec := do(http.MethodPut, "/v1/devices/1", "", "application/json", `{"name":"X","brand":"Acme","state":"available","created_at":`+in+`}`)
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("PUT with created_at %s: %d %s", in, rec.Code, rec.Body.String())
+		}
+	}
+	patch := fmt.Sprintf(`[{"op":"test","path":"/created_at","value":%d},{"op":"replace","path":"/created_at","value":%q},{"op":"replace","path":"/state","value":"inactive"}]`,
+		created.Unix(), created.Format(time.RFC3339))
+	if rec := do(http.MethodPatch, "/v1/devices/1?time_format=epoch", "", "application/json-patch+json", patch); rec.Code != http.StatusNoContent {
+		t.Fatalf("patch: %d %s", rec.Code, rec.Body.String())
+	}
+
+	r = newRouter(db, routers.WithTimeFormat(models.TimeRFC3339))
+	if _, err := time.Parse(time.RFC3339, fmt.Sprint(createdAt(do(http.MethodGet, "/v1/devices/1", "", "", "")))); err != nil {
+		t.Fatalf("expected configured RFC 3339 default: %v", err)
+	}
+}
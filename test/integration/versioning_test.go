@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"bytes"
+	"go-backend/database"
+	"go-backend/internal/routers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVersioning_PrefixAndDeprecatedAliases(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/versions.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deprecation := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := newRouter(db, routers.WithUnversionedDeprecation(deprecation, sunset))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/devices", bytes.NewBufferString(`{"name":"X","brand":"Acme","state":"available"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Deprecation") != "" {
+		t.Fatalf("/v1 must not be deprecated")
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/devices/1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected alias to serve the device, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Deprecation"); got != "@1767225600" {
+		t.Fatalf("unexpected Deprecation header %q", got)
+	}
+	if got := rec.Header().Get("Sunset"); got != "Fri, 01 Jan 2027 00:00:00 GMT" {
+		t.Fatalf("unexpected Sunset header %q", got)
+	}
+	if got := rec.Header().Get("Link"); got != `</v1/devices/1>; rel="successor-version"` {
+		t.Fatalf("unexpected Link header %q", got)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Header().Get("Deprecation") != "" {
+		t.Fatalf("unversioned routes must not be deprecated")
+	}
+}
+
+func TestVersioning_SpecMarksAliasesDeprecated(t *testing.T) {
+	doc := routers.Spec()
+	if op := doc.Operation(http.MethodGet, "/v1/devices/:id"); op == nil || op.Deprecated || op.OperationID != "getDevice" {
+		t.Fatalf("unexpected /v1 operation: %+v", op)
+	}
+	if op := doc.Operation(http.MethodGet, "/devices/:id"); op == nil || !op.Deprecated || op.OperationID != "getDeviceUnversioned" {
+		t.Fatalf("unexpected alias operation: %+v", op)
+	}
+}
@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"encoding/json"
+	"go-backend/config"
+	"go-backend/database"
+	"go-backend/internal/dto"
+	"go-backend/internal/middlewares"
+	"go-backend/internal/routers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhooks_CRUD(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/webhooks.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db)
+	do := requester(r)
+
+	rec := do(http.MethodPost, "/webhooks", `{"url":"https://example.com/hook","event_types":["created","deleted"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created dto.WebhookResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &created)
+	if created.Secret == "" || !created.Active || len(created.EventTypes) != 2 {
+		t.Fatalf("unexpected webhook: %+v", created)
+	}
+
+	rec = do(http.MethodGet, "/webhooks/1", "")
+	var got dto.WebhookResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &got)
+	if rec.Code != http.StatusOK || got.Secret != "" {
+		t.Fatalf("expected secret to be hidden, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodPut, "/webhooks/1", `{"url":"https://example.com/v2","active":false}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodPost, "/webhooks", `{"url":"https://example.com","event_types":["nope"]}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodGet, "/webhooks/1/deliveries?status=dead", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodGet, "/webhooks/dead-letters", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodPost, "/webhooks/deliveries/7/retry", ""); rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodDelete, "/webhooks/1", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodGet, "/webhooks/1", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestWebhooks_RequireAuth(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/webhooks-auth.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter(db, routers.WithAuth(config.AuthConfig{Enabled: true, APIKeys: []string{"k1"}}))
+	do := requester(r)
+	if rec := do(http.MethodPost, "/v1/webhooks", `{"url":"https://example.com/hook"}`); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous create: expected 401, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/v1/webhooks/dead-letters", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous dead letters: expected 401, got %d", rec.Code)
+	}
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1/webhooks", nil)
+	req.Header.Set(middlewares.APIKeyHeader, "k1")
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("with api key: expected 200, got %d", rec.Code)
+	}
+}
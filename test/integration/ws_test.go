@@ -0,0 +1,66 @@
+package integration
+
+import (
+	"bytes"
+	"go-backend/database"
+	"go-backend/internal/dto"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocket_SubscribeAndCommand(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/ws.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(newRouter(db))
+	defer srv.Close()
+	res, err := http.Post(srv.URL+"/devices", "application/json", bytes.NewBufferString(`{"name":"K","brand":"Acme","state":"available"}`))
+	if err != nil || res.StatusCode != http.StatusCreated {
+		t.Fatalf("create failed: %v", err)
+	}
+	res.Body.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/devices/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	read := func() dto.WSServerMessage {
+		var msg dto.WSServerMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	_ = conn.WriteJSON(dto.WSClientMessage{Type: dto.WSSubscribe, ID: "s1", DeviceIDs: []int64{1}})
+	if msg := read(); msg.Type != dto.WSAck || msg.ID != "s1" || len(msg.Subscriptions) != 1 {
+		t.Fatalf("unexpected subscribe reply: %+v", msg)
+	}
+
+	_ = conn.WriteJSON(dto.WSClientMessage{Type: dto.WSCommand, ID: "c1", Command: dto.WSCheckOut, DeviceID: 1})
+	var gotAck, gotEvent bool
+	for !gotAck || !gotEvent {
+		msg := read()
+		switch msg.Type {
+		case dto.WSAck:
+			gotAck = msg.ID == "c1" && msg.Device != nil && msg.Device.State == "in-use"
+		case dto.WSEvent:
+			gotEvent = msg.Event.Type == "patched" && msg.Event.Device.State == "in-use"
+		default:
+			t.Fatalf("unexpected message: %+v", msg)
+		}
+	}
+
+	_ = conn.WriteJSON(dto.WSClientMessage{Type: dto.WSCommand, ID: "c2", Command: dto.WSCheckOut, DeviceID: 1})
+	if msg := read(); msg.Type != dto.WSError || msg.Error.Code != "device_not_available" {
+		t.Fatalf("expected device_not_available error, got %+v", msg)
+	}
+}
@@ -0,0 +1,187 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"go-backend/database"
+	"go-backend/internal/cache"
+	"go-backend/internal/models"
+	"go-backend/internal/repositories"
+	"go-backend/internal/services"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestLRU_EvictionAndTTL(t *testing.T) {
+	c := cache.NewLRU[int64, string](2, 50*time.Millisecond)
+	c.Set(1, "a")
+	c.Set(2, "b")
+	if _, ok := c.Get(1); !ok {
+		t.Fatal("expected 1 cached")
+	}
+	c.Set(3, "c") // evicts 2, the least recently used
+	if _, ok := c.Get(2); ok {
+		t.Fatal("expected 2 evicted")
+	}
+	if v, ok := c.Get(3); !ok || v != "c" {
+		t.Fatalf("expected c, got %q %v", v, ok)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if _, ok := c.Get(1); ok {
+		t.Fatal("expected 1 expired")
+	}
+	s := c.Stats()
+	if s.Hits != 2 || s.Misses != 2 || s.Evictions != 1 || s.Expirations != 1 || s.Entries != 1 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+}
+
+func newCachedService(t *testing.T) (*gorm.DB, *services.DeviceService) {
+	t.Helper()
+	db, err := database.Connect(t.TempDir() + "/cache.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lru := cache.NewLRU[int64, models.Device](100, time.Minute)
+	return db, services.NewDeviceService(repositories.NewDeviceRepository(db), services.WithCache(lru))
+}
+
+func TestService_CacheInvalidatedOnWrites(t *testing.T) {
+	ctx := context.Background()
+	_, svc := newCachedService(t)
+	id, err := svc.Create(ctx, &models.Device{Name: "A", Brand: "B", State: models.StateAvailable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func() *models.Device {
+		t.Helper()
+		d, err := svc.Get(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	get().Name = "mutated by caller"
+	if d := get(); d.Name != "A" {
+		t.Fatalf("cached copy was shared: %+v", d)
+	}
+	if s, _ := svc.CacheStats(); s.Hits != 1 || s.Misses != 1 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+
+	d := get()
+	d.Name = "A2"
+	if err := svc.Update(ctx, id, d); err != nil {
+		t.Fatal(err)
+	}
+	if d := get(); d.Name != "A2" {
+		t.Fatalf("stale after update: %+v", d)
+	}
+	if err := svc.Patch(ctx, id, map[string]any{"brand": "C"}); err != nil {
+		t.Fatal(err)
+	}
+	if d := get(); d.Brand != "C" {
+		t.Fatalf("stale after patch: %+v", d)
+	}
+	if err := svc.PatchIfUnchanged(ctx, get(), map[string]any{"name": "A3"}); err != nil {
+		t.Fatal(err)
+	}
+	if d := get(); d.Name != "A3" {
+		t.Fatalf("stale after conditional patch: %+v", d)
+	}
+	if _, err := svc.CheckOut(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if d := get(); d.State != models.StateInUse {
+		t.Fatalf("stale after check-out: %+v", d)
+	}
+	if _, err := svc.CheckIn(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Delete(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Get(ctx, id); !errors.Is(err, models.ErrDeviceNotFound) {
+		t.Fatalf("expected not found after delete, got %v", err)
+	}
+}
+
+func TestService_CacheCoalescesMisses(t *testing.T) {
+	ctx := context.Background()
+	db, svc := newCachedService(t)
+	id, err := svc.Create(ctx, &models.Device{Name: "A", Brand: "B", State: models.StateAvailable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var queries atomic.Int64
+	if err := db.Callback().Query().After("gorm:query").Register("count", func(*gorm.DB) { queries.Add(1) }); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.Get(ctx, id); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := queries.Load(); n != 1 {
+		t.Fatalf("expected one repository read, got %d", n)
+	}
+}
+
+func TestService_CacheBypassedForSelectedColumns(t *testing.T) {
+	ctx := context.Background()
+	_, svc := newCachedService(t)
+	id, err := svc.Create(ctx, &models.Device{Name: "A", Brand: "B", State: models.StateAvailable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Get(ctx, id, "id", "name"); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := svc.CacheStats(); s.Hits+s.Misses != 0 || s.Entries != 0 {
+		t.Fatalf("sparse read used the cache: %+v", s)
+	}
+}
+
+func TestService_CacheHandsOutCopies(t *testing.T) {
+	ctx := context.Background()
+	_, svc := newCachedService(t)
+	serial := "SN-1"
+	id, err := svc.Create(ctx, &models.Device{
+		Name: "A", Brand: "B", State: models.StateAvailable, SerialNumber: &serial,
+		Attributes: models.Attributes{"ports": map[string]any{"usb": 2.0}, "bands": []any{"2.4", "5"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 { // a miss that fills the cache, then a hit
+		d, err := svc.Get(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.Attributes["color"] = "red"
+		d.Attributes["ports"].(map[string]any)["usb"] = 9.0
+		d.Attributes["bands"].([]any)[0] = "6"
+		*d.SerialNumber = "changed"
+	}
+	d, err := svc.Get(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := d.Attributes["color"]; ok || d.Attributes["ports"].(map[string]any)["usb"] != 2.0 ||
+		d.Attributes["bands"].([]any)[0] != "2.4" || *d.SerialNumber != "SN-1" {
+		t.Fatalf("cached device was modified through a returned copy: %+v %q", d.Attributes, *d.SerialNumber)
+	}
+	if s, _ := svc.CacheStats(); s.Hits != 2 || s.Misses != 1 {
+		t.Fatalf("expected reads through the cache: %+v", s)
+	}
+}
@@ -0,0 +1,73 @@
+package unit
+
+import (
+	"go-backend/config"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestConfig_Precedence(t *testing.T) {
+	file := t.TempDir() + "/config.yaml"
+	_ = os.WriteFile(file, []byte("server:\n  addr: \":9000\"\ndatabase:\n  path: /file.db\nlogging:\n  level: warn\n"), 0644)
+	t.Setenv("DB_PATH", "/env.db")
+	t.Setenv("LOGGING_LEVEL", "error")
+	cfg, err := config.Load([]string{"--config", file, "--logging.level", "debug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Addr != ":9000" {
+		t.Fatalf("expected file value, got %s", cfg.Server.Addr)
+	}
+	if cfg.Database.Path != "/env.db" {
+		t.Fatalf("expected env value, got %s", cfg.Database.Path)
+	}
+	if cfg.Logging.Level != "debug" {
+		t.Fatalf("expected flag value, got %s", cfg.Logging.Level)
+	}
+	if cfg.Server.ShutdownTimeout.String() != "20s" {
+		t.Fatalf("expected default value, got %s", cfg.Server.ShutdownTimeout)
+	}
+}
+
+func TestConfig_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := config.Load([]string{"--config", dir + "/missing.yaml"}); err == nil {
+		t.Fatalf("expected error for missing explicit config file")
+	}
+	_ = os.WriteFile(dir+"/bad.yaml", []byte("server: [\n"), 0644)
+	if _, err := config.Load([]string{"--config", dir + "/bad.yaml"}); err == nil {
+		t.Fatalf("expected error for malformed config file")
+	}
+	_ = os.WriteFile(dir+"/typo.yaml", []byte("server:\n  shutdown_timout: 5s\n"), 0644)
+	if _, err := config.Load([]string{"--config", dir + "/typo.yaml"}); err == nil || !strings.Contains(err.Error(), "shutdown_timout") {
+		t.Fatalf("expected error for unknown key, got %v", err)
+	}
+	t.Setenv("LOGGING_FORMAT", "xml")
+	t.Setenv("SERVER_READ_TIMEOUT", "-1s")
+	t.Setenv("AUTH_ENABLED", "true")
+	t.Setenv("GRPC_ADDR", ":8080")
+	t.Setenv("API_UNVERSIONED_SUNSET", "2020-01-01T00:00:00Z")
+	t.Setenv("API_TIME_FORMAT", "iso")
+	t.Setenv("DEVICE_CACHE_TTL", "0s")
+	_, err := config.Load(nil)
+	if err == nil {
+		t.Fatalf("expected validation error")
+	}
+	for _, want := range []string{"logging.format", "server.read_timeout", "auth.enabled", "grpc.addr", "api.unversioned_sunset", "api.time_format", "device_cache.ttl"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in %v", want, err)
+		}
+	}
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := config.Config{Auth: config.AuthConfig{JWTSecret: "s3cr3t", APIKeys: []string{"k1"}}}
+	out, err := cfg.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "s3cr3t") || strings.Contains(string(out), "k1") {
+		t.Fatalf("secrets leaked: %s", out)
+	}
+}
@@ -0,0 +1,62 @@
+package unit
+
+import (
+	"go-backend/internal/events"
+	"go-backend/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestBroker_Replay(t *testing.T) {
+	b := events.NewBroker(2)
+	var ids []string
+	for i := 0; i < 3; i++ {
+		ids = append(ids, b.Publish(events.Created, models.Device{ID: int64(i + 1)}).ID)
+	}
+	sub, replay, complete := b.Subscribe(ids[0], 1, nil)
+	sub.Close()
+	if !complete || len(replay) != 2 || replay[0].ID != ids[1] || replay[1].ID != ids[2] {
+		t.Fatalf("unexpected replay: %+v complete=%v", replay, complete)
+	}
+	b.Publish(events.Created, models.Device{ID: 4})
+	sub, replay, complete = b.Subscribe(ids[0], 1, nil)
+	sub.Close()
+	if complete || len(replay) != 2 {
+		t.Fatalf("expected incomplete replay after eviction: %+v complete=%v", replay, complete)
+	}
+	epoch, _, _ := strings.Cut(ids[0], "-")
+	for _, id := range []string{epoch + "-10", "10", "OTHER-1"} {
+		sub, replay, complete = b.Subscribe(id, 1, nil)
+		sub.Close()
+		if complete || len(replay) != 0 {
+			t.Fatalf("expected reset without replay for %s: %+v complete=%v", id, replay, complete)
+		}
+	}
+}
+
+func TestBroker_EpochPerInstance(t *testing.T) {
+	first := events.NewBroker(0).Publish(events.Created, models.Device{ID: 1})
+	restarted := events.NewBroker(0)
+	restarted.Publish(events.Created, models.Device{ID: 1})
+	restarted.Publish(events.Created, models.Device{ID: 2})
+	sub, replay, complete := restarted.Subscribe(first.ID, 1, nil)
+	sub.Close()
+	if complete || len(replay) != 0 {
+		t.Fatalf("expected a restarted broker to reset %s: %+v complete=%v", first.ID, replay, complete)
+	}
+}
+
+func TestBroker_SlowConsumerDisconnected(t *testing.T) {
+	b := events.NewBroker(0)
+	sub, _, _ := b.Subscribe("", 1, func(ev events.Event) bool { return ev.Device.Brand == "Acme" })
+	b.Publish(events.Created, models.Device{ID: 1, Brand: "Other"})
+	b.Publish(events.Created, models.Device{ID: 2, Brand: "Acme"})
+	b.Publish(events.Created, models.Device{ID: 3, Brand: "Acme"})
+	if ev, ok := <-sub.C; !ok || ev.Device.ID != 2 {
+		t.Fatalf("expected filtered event 2, got %+v", ev)
+	}
+	if _, ok := <-sub.C; ok || !sub.Dropped() {
+		t.Fatalf("expected slow subscriber to be disconnected")
+	}
+	sub.Close()
+}
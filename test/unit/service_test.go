@@ -33,14 +33,14 @@ func TestService_CreateGetList(t *testing.T) {
 	if d.ID != id1 || d.Name != "Phone X" || d.Brand != "Acme" || d.State != models.StateAvailable {
 		t.Fatalf("unexpected device: %+v", d)
 	}
-	list, err := svc.List(context.Background(), "Acme", "")
+	list, err := svc.List(context.Background(), models.DeviceFilter{Brand: "Acme"})
 	if err != nil {
 		t.Fatal(err)
 	}
 	if len(list) != 2 {
 		t.Fatalf("expected 2, got %d", len(list))
 	}
-	list, err = svc.List(context.Background(), "", string(models.StateInactive))
+	list, err = svc.List(context.Background(), models.DeviceFilter{State: string(models.StateInactive)})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -164,7 +164,7 @@ func TestService_ListCombinedFilter(t *testing.T) {
 	svc := services.NewDeviceService(repositories.NewDeviceRepository(db))
 	_, _ = svc.Create(context.Background(), &models.Device{Name: "P1", Brand: "Acme", State: models.StateAvailable})
 	_, _ = svc.Create(context.Background(), &models.Device{Name: "P2", Brand: "Acme", State: models.StateInactive})
-	list, err := svc.List(context.Background(), "Acme", string(models.StateAvailable))
+	list, err := svc.List(context.Background(), models.DeviceFilter{Brand: "Acme", State: string(models.StateAvailable)})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -172,3 +172,86 @@ func TestService_ListCombinedFilter(t *testing.T) {
 		t.Fatalf("unexpected list: %+v", list)
 	}
 }
+
+func TestService_CheckOutCheckIn(t *testing.T) {
+	path := t.TempDir() + "/unit7.db"
+	db, err := database.Connect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := services.NewDeviceService(repositories.NewDeviceRepository(db))
+	id, err := svc.Create(context.Background(), &models.Device{Name: "G", Brand: "H", State: models.StateAvailable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CheckIn(context.Background(), id); err != models.ErrNotInUse {
+		t.Fatalf("expected ErrNotInUse, got %v", err)
+	}
+	d, err := svc.CheckOut(context.Background(), id)
+	if err != nil || d.State != models.StateInUse {
+		t.Fatalf("unexpected checkout result: %+v %v", d, err)
+	}
+	if _, err := svc.CheckOut(context.Background(), id); err != models.ErrNotAvailable {
+		t.Fatalf("expected ErrNotAvailable, got %v", err)
+	}
+	if _, err := svc.CheckIn(context.Background(), id); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+func TestService_PatchIfUnchanged(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/unit-patch.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := services.NewDeviceService(repositories.NewDeviceRepository(db))
+	ctx := context.Background()
+	id, err := svc.Create(ctx, &models.Device{Name: "Phone", Brand: "Acme", State: models.StateAvailable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := svc.Get(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Patch(ctx, id, map[string]any{"brand": "Other"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.PatchIfUnchanged(ctx, snapshot, map[string]any{"name": "Renamed"}); err != models.ErrConcurrentModification {
+		t.Fatalf("expected ErrConcurrentModification, got %v", err)
+	}
+	fresh, _ := svc.Get(ctx, id)
+	if err := svc.PatchIfUnchanged(ctx, fresh, map[string]any{"name": "Renamed"}); err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := svc.Get(ctx, id); d.Name != "Renamed" || d.Brand != "Other" {
+		t.Fatalf("unexpected device: %+v", d)
+	}
+}
+
+func TestService_SelectedColumns(t *testing.T) {
+	db, err := database.Connect(t.TempDir() + "/unit-columns.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := services.NewDeviceService(repositories.NewDeviceRepository(db))
+	ctx := context.Background()
+	id, err := svc.Create(ctx, &models.Device{Name: "Phone", Brand: "Acme", State: models.StateAvailable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := svc.Get(ctx, id, "name", "state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Name != "Phone" || d.State != models.StateAvailable || d.Brand != "" || !d.CreatedAt.IsZero() {
+		t.Fatalf("expected only name and state to be read: %+v", d)
+	}
+	list, err := svc.List(ctx, models.DeviceFilter{Brand: "Acme"}, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != id || list[0].Name != "" {
+		t.Fatalf("expected only ids to be read: %+v", list)
+	}
+}
@@ -0,0 +1,327 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"go-backend/config"
+	"go-backend/database"
+	"go-backend/internal/dto"
+	"go-backend/internal/models"
+	"go-backend/internal/repositories"
+	"go-backend/internal/services"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func webhookConfig() config.WebhooksConfig {
+	cfg := config.Default().Webhooks
+	cfg.InitialBackoff = time.Millisecond
+	cfg.MaxBackoff = time.Millisecond
+	cfg.MaxAttempts = 2
+	// The test receivers listen on loopback.
+	cfg.AllowPrivateNetworks = true
+	return cfg
+}
+
+func newWebhookFixture(t *testing.T) (*gorm.DB, *services.DeviceService, *services.WebhookService, *services.WebhookDispatcher) {
+	t.Helper()
+	db, err := database.Connect(t.TempDir() + "/webhooks.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := repositories.NewWebhookRepository(db)
+	return db, services.NewDeviceService(repositories.NewDeviceRepository(db)), services.NewWebhookService(repo, webhookConfig()), services.NewWebhookDispatcher(repo, webhookConfig(), models.TimeEpoch)
+}
+
+func TestWebhook_SignedDelivery(t *testing.T) {
+	_, devices, hooks, dispatcher := newWebhookFixture(t)
+	ctx := context.Background()
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	got := make(chan received, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got <- received{r.Header.Clone(), b}
+	}))
+	defer srv.Close()
+
+	sub, err := hooks.Create(ctx, srv.URL, []string{"state_changed"}, "s3cret", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := devices.Create(ctx, &models.Device{Name: "Phone", Brand: "Acme", State: models.StateAvailable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := devices.CheckOut(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if err := dispatcher.Process(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected exactly the state_changed delivery, got %d", len(got))
+	}
+	r := <-got
+	var p dto.WebhookPayload
+	if err := json.Unmarshal(r.body, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Type != "state_changed" || p.Device.ID != id || p.Device.State != "in-use" {
+		t.Fatalf("unexpected payload: %+v", p)
+	}
+	// Timestamps follow the configured time format, epoch here.
+	var raw struct {
+		OccurredAt any `json:"occurred_at"`
+		Device     struct {
+			CreatedAt any `json:"created_at"`
+		} `json:"device"`
+	}
+	_ = json.Unmarshal(r.body, &raw)
+	if _, ok := raw.OccurredAt.(float64); !ok {
+		t.Fatalf("expected epoch occurred_at, got %s", r.body)
+	}
+	if _, ok := raw.Device.CreatedAt.(float64); !ok {
+		t.Fatalf("expected epoch created_at, got %s", r.body)
+	}
+	ts := r.header.Get(services.TimestampHeader)
+	if want := "t=" + ts + ",v1=" + services.Sign("s3cret", ts, r.body); r.header.Get(services.SignatureHeader) != want {
+		t.Fatalf("bad signature %q, want %q", r.header.Get(services.SignatureHeader), want)
+	}
+	list, err := hooks.Deliveries(ctx, sub.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Status != models.DeliverySucceeded || list[0].Attempts != 1 {
+		t.Fatalf("unexpected delivery log: %+v", list)
+	}
+	if err := dispatcher.Process(ctx); err != nil || len(got) != 0 {
+		t.Fatalf("expected no redelivery, err=%v got=%d", err, len(got))
+	}
+}
+
+func TestWebhook_RetryAndDeadLetter(t *testing.T) {
+	_, devices, hooks, dispatcher := newWebhookFixture(t)
+	ctx := context.Background()
+	var calls, fail atomic.Int32
+	fail.Store(1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if fail.Load() == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	if _, err := hooks.Create(ctx, srv.URL, nil, "", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := devices.Create(ctx, &models.Device{Name: "Phone", Brand: "Acme", State: models.StateAvailable}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := dispatcher.Process(ctx); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 attempts, got %d", calls.Load())
+	}
+	dead, err := hooks.DeadLetters(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dead) != 1 || dead[0].LastStatusCode != http.StatusInternalServerError || !strings.Contains(dead[0].LastError, "500") {
+		t.Fatalf("unexpected dead letters: %+v", dead)
+	}
+
+	fail.Store(0)
+	if err := hooks.Retry(ctx, dead[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := hooks.Retry(ctx, dead[0].ID); err != models.ErrDeliveryNotRetrying {
+		t.Fatalf("expected ErrDeliveryNotRetrying, got %v", err)
+	}
+	if err := dispatcher.Process(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if dead, _ := hooks.DeadLetters(ctx); len(dead) != 0 || calls.Load() != 3 {
+		t.Fatalf("expected retried delivery to succeed, calls=%d dead=%d", calls.Load(), len(dead))
+	}
+}
+
+func TestWebhook_Validation(t *testing.T) {
+	_, _, hooks, _ := newWebhookFixture(t)
+	ctx := context.Background()
+	if _, err := hooks.Create(ctx, "ftp://example.com", nil, "", true); err != models.ErrInvalidWebhookURL {
+		t.Fatalf("expected ErrInvalidWebhookURL, got %v", err)
+	}
+	if _, err := hooks.Create(ctx, "https://example.com/hook", []string{"exploded"}, "", true); err != models.ErrInvalidEventType {
+		t.Fatalf("expected ErrInvalidEventType, got %v", err)
+	}
+	sub, err := hooks.Create(ctx, "https://example.com/hook", nil, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sub.Secret) != 64 {
+		t.Fatalf("expected generated secret, got %q", sub.Secret)
+	}
+}
+
+func TestWebhook_Egress(t *testing.T) {
+	db, devices, _, _ := newWebhookFixture(t)
+	ctx := context.Background()
+	repo := repositories.NewWebhookRepository(db)
+	strict := config.Default().Webhooks
+	hooks := services.NewWebhookService(repo, strict)
+	for _, u := range []string{
+		"http://127.0.0.1:8080/hook", "http://localhost/hook", "http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.7/hook", "http://[::1]/hook", "http://[::ffff:192.168.1.1]/hook", "http://0.0.0.0/hook",
+	} {
+		if _, err := hooks.Create(ctx, u, nil, "", true); err != models.ErrWebhookHostDenied {
+			t.Fatalf("%s: expected ErrWebhookHostDenied, got %v", u, err)
+		}
+	}
+	if _, err := hooks.Create(ctx, "https://example.com/hook", nil, "", true); err != nil {
+		t.Fatal(err)
+	}
+
+	listed := strict
+	listed.AllowedHosts = []string{"*.example.com"}
+	hooks = services.NewWebhookService(repo, listed)
+	if _, err := hooks.Create(ctx, "https://example.org/hook", nil, "", true); err != models.ErrWebhookHostDenied {
+		t.Fatalf("expected ErrWebhookHostDenied outside the allowlist, got %v", err)
+	}
+	if _, err := hooks.Create(ctx, "https://Hooks.Example.com/hook", nil, "", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// A subscription written before the rules were tightened is not called.
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls.Add(1) }))
+	defer srv.Close()
+	loopback, err := services.NewWebhookService(repo, webhookConfig()).Create(ctx, srv.URL, nil, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := devices.Create(ctx, &models.Device{Name: "Phone", Brand: "Acme", State: models.StateAvailable}); err != nil {
+		t.Fatal(err)
+	}
+	strict.MaxAttempts = 1
+	if err := services.NewWebhookDispatcher(repo, strict, models.TimeLegacy).Process(ctx); err != nil {
+		t.Fatal(err)
+	}
+	dead, err := hooks.Deliveries(ctx, loopback.ID, string(models.DeliveryDead))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 0 || len(dead) != 1 || dead[0].LastError != models.ErrWebhookHostDenied.Error() {
+		t.Fatalf("expected the loopback delivery to be refused, calls=%d dead=%+v", calls.Load(), dead)
+	}
+}
+
+func TestWebhook_Prune(t *testing.T) {
+	db, devices, hooks, dispatcher := newWebhookFixture(t)
+	ctx := context.Background()
+	var fail atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	if _, err := hooks.Create(ctx, srv.URL, nil, "", true); err != nil {
+		t.Fatal(err)
+	}
+	id, err := devices.Create(ctx, &models.Device{Name: "Phone", Brand: "Acme", State: models.StateAvailable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dispatcher.Process(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Still within the retention period: nothing goes.
+	if n, err := dispatcher.Prune(ctx, time.Now()); err != nil || n != 0 {
+		t.Fatalf("prune within retention: %d %v", n, err)
+	}
+	// An event whose delivery is still pending is kept past the retention.
+	fail.Store(1)
+	if _, err := devices.CheckOut(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if err := dispatcher.Process(ctx); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(webhookConfig().Retention + time.Minute)
+	if n, err := dispatcher.Prune(ctx, later); err != nil || n != 1 {
+		t.Fatalf("prune: %d %v", n, err)
+	}
+	var events, deliveries, pending int64
+	db.Model(&models.OutboxEvent{}).Count(&events)
+	db.Model(&models.WebhookDelivery{}).Count(&deliveries)
+	db.Model(&models.WebhookDelivery{}).Where("status = ?", models.DeliveryPending).Count(&pending)
+	if events == 0 || events != deliveries || deliveries != pending {
+		t.Fatalf("expected only the pending deliveries and their events to remain, events=%d deliveries=%d pending=%d", events, deliveries, pending)
+	}
+}
+
+func TestWebhook_UndeliverableGoesDead(t *testing.T) {
+	db, devices, hooks, dispatcher := newWebhookFixture(t)
+	ctx := context.Background()
+	var received atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { received.Add(1) }))
+	defer srv.Close()
+	sub, err := hooks.Create(ctx, srv.URL, []string{"created"}, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := devices.Create(ctx, &models.Device{Name: "Phone", Brand: "Acme", State: models.StateAvailable}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&models.OutboxEvent{}).Where("1 = 1").Update("payload", "{").Error; err != nil {
+		t.Fatal(err)
+	}
+	// A payload that cannot be read is not retried.
+	if err := dispatcher.Process(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var dead []models.WebhookDelivery
+	db.Where("status = ?", models.DeliveryDead).Find(&dead)
+	if len(dead) != 1 || dead[0].SubscriptionID != sub.ID || !strings.Contains(dead[0].LastError, "invalid payload") {
+		t.Fatalf("expected the unreadable delivery to be dead: %+v", dead)
+	}
+
+	// A delivery whose subscription went away is dead; the rest of the batch
+	// is still sent.
+	if _, err := devices.Create(ctx, &models.Device{Name: "Tablet", Brand: "Acme", State: models.StateAvailable}); err != nil {
+		t.Fatal(err)
+	}
+	repo := repositories.NewWebhookRepository(db)
+	if _, err := repo.FanOut(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+	var ev models.OutboxEvent
+	db.Order("id DESC").First(&ev)
+	orphan := models.WebhookDelivery{SubscriptionID: sub.ID + 100, OutboxEventID: ev.ID, EventType: ev.EventType,
+		Status: models.DeliveryPending, NextAttemptAt: time.Now().Add(-time.Minute)}
+	if err := db.Create(&orphan).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := dispatcher.Process(ctx); err != nil {
+		t.Fatal(err)
+	}
+	db.First(&orphan, orphan.ID)
+	if orphan.Status != models.DeliveryDead || received.Load() != 1 {
+		t.Fatalf("orphan %s, received %d", orphan.Status, received.Load())
+	}
+}